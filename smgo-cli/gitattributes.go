@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jriquelme/SemanticMergeGO/smgo"
+)
+
+// gitAttributes are the attributes from .gitattributes relevant to parse a file.
+type gitAttributes struct {
+	// WorkingTreeEncoding is the encoding of the file in the working tree, empty if unspecified.
+	WorkingTreeEncoding string
+	// EOL is "lf" or "crlf", empty if unspecified.
+	EOL string
+}
+
+// lookupGitAttributes resolves the attributes of the file at src from the .gitattributes files in its directory and
+// the directories above it, up to the root of the repository. Deeper files take precedence, like in git.
+func lookupGitAttributes(src string) (gitAttributes, error) {
+	var attrs gitAttributes
+	src, err := filepath.Abs(src)
+	if err != nil {
+		return attrs, err
+	}
+	var dirs []string
+	for dir := filepath.Dir(src); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			break
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(dirs[i], src)
+		if err != nil {
+			return attrs, err
+		}
+		err = applyGitAttributesFile(&attrs, filepath.Join(dirs[i], ".gitattributes"), filepath.ToSlash(rel))
+		if err != nil {
+			return attrs, err
+		}
+	}
+	return attrs, nil
+}
+
+// applyGitAttributesFile applies the lines of the .gitattributes file at name matching rel, the path of the file
+// relative to the directory of name. A missing file is ignored.
+func applyGitAttributesFile(attrs *gitAttributes, name, rel string) error {
+	f, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") || !matchGitPattern(fields[0], rel) {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			applyGitAttribute(attrs, attr)
+		}
+	}
+	return scanner.Err()
+}
+
+func applyGitAttribute(attrs *gitAttributes, attr string) {
+	switch {
+	case strings.HasPrefix(attr, "working-tree-encoding="):
+		attrs.WorkingTreeEncoding = strings.TrimPrefix(attr, "working-tree-encoding=")
+	case attr == "-working-tree-encoding" || attr == "!working-tree-encoding":
+		attrs.WorkingTreeEncoding = ""
+	case strings.HasPrefix(attr, "eol="):
+		attrs.EOL = strings.ToLower(strings.TrimPrefix(attr, "eol="))
+	case attr == "-eol" || attr == "!eol" || attr == "-text" || attr == "binary":
+		// files that aren't text don't get their line endings converted
+		attrs.EOL = ""
+	}
+}
+
+// matchGitPattern reports whether the gitattributes pattern matches rel. A pattern without a slash matches the
+// name of the file at any depth, otherwise it's matched against the whole relative path, where "**" matches any
+// number of directories.
+func matchGitPattern(pattern, rel string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(rel))
+		return ok
+	}
+	pattern = strings.TrimPrefix(pattern, "/")
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], parts[0])
+	return ok && matchSegments(pattern[1:], parts[1:])
+}
+
+// checkGitAttributes looks up the .gitattributes of src and reports to logger when they disagree with encoding (the
+// one given by SemanticMerge) or with the line endings of the file. It returns the encoding to parse src with: the
+// working-tree-encoding if override is set and there is one, encoding otherwise.
+func checkGitAttributes(src, encoding string, override bool, logger *log.Logger) string {
+	attrs, err := lookupGitAttributes(src)
+	if err != nil {
+		logger.Printf("error looking up .gitattributes of %s: %s", src, err)
+		return encoding
+	}
+	if attrs.WorkingTreeEncoding != "" {
+		workingTreeEncoding := gitEncoding(attrs.WorkingTreeEncoding)
+		if !sameEncoding(workingTreeEncoding, encoding) {
+			logger.Printf("%s: working-tree-encoding=%s disagrees with encoding %s", src, attrs.WorkingTreeEncoding, encoding)
+			if _, err := smgo.EncodingName(workingTreeEncoding); err != nil {
+				logger.Printf("%s: unsupported working-tree-encoding=%s", src, attrs.WorkingTreeEncoding)
+			} else if override {
+				encoding = workingTreeEncoding
+			}
+		}
+	}
+	if attrs.EOL != "" {
+		f, err := os.Open(src)
+		if err != nil {
+			logger.Printf("error reading %s: %s", src, err)
+			return encoding
+		}
+		defer f.Close()
+		decoded, err := smgo.Decode(f, encoding)
+		if err != nil {
+			logger.Printf("error decoding %s: %s", src, err)
+			return encoding
+		}
+		if msg := eolDisagreement(attrs.EOL, decoded); msg != "" {
+			logger.Printf("%s: %s", src, msg)
+		}
+	}
+	return encoding
+}
+
+// gitEncoding returns the name of a working-tree-encoding for smgo. Git names like UTF-16LE-BOM (from iconv) are the
+// encoding with a byte order mark, which is read anyway.
+func gitEncoding(name string) string {
+	const bom = "-BOM"
+	if len(name) > len(bom) && strings.EqualFold(name[len(name)-len(bom):], bom) {
+		return name[:len(name)-len(bom)]
+	}
+	return name
+}
+
+// sameEncoding reports whether the names a and b are the same encoding, comparing the names if they're unsupported.
+func sameEncoding(a, b string) bool {
+	canonicalA, errA := smgo.EncodingName(a)
+	canonicalB, errB := smgo.EncodingName(b)
+	if errA != nil || errB != nil {
+		return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+	}
+	return canonicalA == canonicalB
+}
+
+// eolDisagreement returns a description of the line endings in src not matching eol, empty if they all match.
+func eolDisagreement(eol string, src []byte) string {
+	crlf := strings.Count(string(src), "\r\n")
+	lf := strings.Count(string(src), "\n") - crlf
+	switch {
+	case eol == "crlf" && lf > 0:
+		return "eol=crlf but found lines ending in LF"
+	case eol == "lf" && crlf > 0:
+		return "eol=lf but found lines ending in CRLF"
+	}
+	return ""
+}
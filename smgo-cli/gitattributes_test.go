@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jriquelme/SemanticMergeGO/smgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupGitAttributes(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "smgo-gitattributes")
+	require.Nil(t, err)
+	defer os.RemoveAll(tmp)
+	root := filepath.Join(tmp, "repo")
+
+	files := map[string]string{
+		".gitattributes":          "# encodings\n*.go working-tree-encoding=UTF-16LE eol=crlf\n/legacy/**/*.go working-tree-encoding=windows-1252\n",
+		"pkg/.gitattributes":      "gen_*.go -working-tree-encoding -text\n",
+		"legacy/a/.gitattributes": "b.go eol=lf\n",
+	}
+	for name, content := range files {
+		name = filepath.Join(root, filepath.FromSlash(name))
+		require.Nil(t, os.MkdirAll(filepath.Dir(name), 0755))
+		require.Nil(t, ioutil.WriteFile(name, []byte(content), 0644))
+	}
+	// the lookup stops at the root of the repository
+	require.Nil(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(tmp, ".gitattributes"), []byte("*.go eol=lf\n"), 0644))
+
+	cases := []struct {
+		Src      string
+		Expected gitAttributes
+	}{
+		{"main.go", gitAttributes{"UTF-16LE", "crlf"}},
+		{"main.txt", gitAttributes{}},
+		{"pkg/x.go", gitAttributes{"UTF-16LE", "crlf"}},
+		{"pkg/gen_x.go", gitAttributes{}},
+		{"legacy/a/b.go", gitAttributes{"windows-1252", "lf"}},
+		{"legacy/a/c.go", gitAttributes{"windows-1252", "crlf"}},
+		{"legacy/c.go", gitAttributes{"windows-1252", "crlf"}},
+	}
+	for _, testCase := range cases {
+		attrs, err := lookupGitAttributes(filepath.Join(root, filepath.FromSlash(testCase.Src)))
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.Expected, attrs, testCase.Src)
+	}
+}
+
+func TestEOLDisagreement(t *testing.T) {
+	assert.Equal(t, "", eolDisagreement("crlf", []byte("a\r\nb\r\n")))
+	assert.Equal(t, "eol=crlf but found lines ending in LF", eolDisagreement("crlf", []byte("a\r\nb\n")))
+	assert.Equal(t, "", eolDisagreement("lf", []byte("a\nb\n")))
+	assert.Equal(t, "eol=lf but found lines ending in CRLF", eolDisagreement("lf", []byte("a\nb\r\n")))
+}
+
+func TestGitEncoding(t *testing.T) {
+	assert.Equal(t, "UTF-16LE", gitEncoding("UTF-16LE-BOM"))
+	assert.Equal(t, "utf-32be", gitEncoding("utf-32be-bom"))
+	assert.Equal(t, "UTF-16", gitEncoding("UTF-16"))
+
+	assert.True(t, sameEncoding("utf8", "UTF-8"))
+	assert.True(t, sameEncoding("latin1", "ISO-8859-1"))
+	assert.True(t, sameEncoding("UTF-16LE", gitEncoding("UTF-16LE-BOM")))
+	assert.False(t, sameEncoding("UTF-8", "UTF-16LE"))
+	assert.True(t, sameEncoding("UTF-32LE", "utf-32le"))
+}
+
+func TestCheckGitAttributes(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "smgo-gitattributes")
+	require.Nil(t, err)
+	defer os.RemoveAll(tmp)
+	require.Nil(t, os.Mkdir(filepath.Join(tmp, ".git"), 0755))
+	attributes := "utf16.go working-tree-encoding=UTF-16LE-BOM\nlatin1.go working-tree-encoding=latin1\n" +
+		"utf32.go working-tree-encoding=UTF-32LE-BOM\n"
+	require.Nil(t, ioutil.WriteFile(filepath.Join(tmp, ".gitattributes"), []byte(attributes), 0644))
+
+	var out bytes.Buffer
+	logger := log.New(&out, "", 0)
+
+	assert.Equal(t, "UTF-16LE", checkGitAttributes(filepath.Join(tmp, "utf16.go"), "UTF-8", true, logger))
+	assert.Contains(t, out.String(), "working-tree-encoding=UTF-16LE-BOM disagrees with encoding UTF-8")
+	_, err = smgo.Decode(bytes.NewReader([]byte("\xff\xfep\x00")), "UTF-16LE")
+	assert.Nil(t, err)
+
+	// aliases agree
+	out.Reset()
+	assert.Equal(t, "ISO-8859-1", checkGitAttributes(filepath.Join(tmp, "latin1.go"), "ISO-8859-1", true, logger))
+	assert.Empty(t, out.String())
+
+	// unsupported encodings don't override
+	out.Reset()
+	assert.Equal(t, "UTF-8", checkGitAttributes(filepath.Join(tmp, "utf32.go"), "UTF-8", true, logger))
+	assert.Contains(t, out.String(), "unsupported working-tree-encoding=UTF-32LE-BOM")
+}
@@ -2,7 +2,12 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"os"
 
@@ -10,14 +15,68 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+var gitAttributesMode = flag.String("gitattributes", "",
+	"look up .gitattributes for each file: \"check\" reports when it disagrees with the encoding from SemanticMerge, "+
+		"\"override\" also uses its working-tree-encoding")
+
+var generatedMode = flag.String("generated", "",
+	"\"coarse\" emits the declarations of generated files (with a \"Code generated ... DO NOT EDIT.\" comment) as a "+
+		"single node")
+
+var importSections = flag.Bool("importsections", false,
+	"split import groups into sections separated by blank lines (std, module-local and third-party, after the "+
+		"nearest go.mod)")
+
+const usage = "use smgo-cli [-gitattributes check|override] [-generated coarse] [-importsections] shell <flag file path>, smgo-cli verify <file> [encoding] " +
+	"or smgo-cli selftest [dir]"
+
 func main() {
-	if len(os.Args) != 3 {
-		log.Fatalln("invalid arguments: use smgo-cli shell <flag file path>")
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		log.Fatalln("invalid arguments: " + usage)
 	}
-	if os.Args[1] != "shell" {
-		log.Fatalln("invalid arguments: use smgo-cli shell <flag file path>")
+	switch *gitAttributesMode {
+	case "", "check", "override":
+	default:
+		log.Fatalf("invalid -gitattributes mode: %s", *gitAttributesMode)
+	}
+	switch *generatedMode {
+	case "", "coarse":
+	default:
+		log.Fatalf("invalid -generated mode: %s", *generatedMode)
+	}
+	switch {
+	case args[0] == "shell" && len(args) == 2:
+		shell(args[1], os.Stdin, os.Stdout)
+	case args[0] == "verify" && len(args) <= 3:
+		encoding := "UTF-8"
+		if len(args) == 3 {
+			encoding = args[2]
+		}
+		if !verify(args[1], encoding) {
+			os.Exit(1)
+		}
+	case args[0] == "selftest" && len(args) <= 2:
+		root := goRootSrc()
+		if len(args) == 2 {
+			root = args[1]
+		}
+		ok, err := selfTest(root, os.Stdout)
+		if err != nil {
+			log.Fatalf("error walking %s: %s", root, err)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+	default:
+		log.Fatalln("invalid arguments: " + usage)
 	}
-	flagFilePath := os.Args[2]
+}
+
+// shell runs the external parser protocol of SemanticMerge, reading the requests from in and writing the results to
+// out.
+func shell(flagFilePath string, in io.Reader, out io.Writer) {
 	flagFile, err := os.Create(flagFilePath)
 	if err != nil {
 		log.Fatalf("error creating flag file: %s", err)
@@ -31,7 +90,7 @@ func main() {
 		log.Fatalf("error closing flag file: %s", err)
 	}
 
-	scanner := bufio.NewScanner(os.Stdin)
+	scanner := bufio.NewScanner(in)
 	for scanner.Scan() {
 		srcOrEnd := scanner.Text()
 		if srcOrEnd == "end" {
@@ -48,21 +107,56 @@ func main() {
 
 		err := parse(srcOrEnd, encoding, output)
 		if err != nil {
-			fmt.Println("KO")
+			log.Printf("error parsing %s: %s", srcOrEnd, err)
+			fmt.Fprintln(out, "KO")
 		} else {
-			fmt.Println("OK")
+			fmt.Fprintln(out, "OK")
 		}
 	}
 }
 
-func parse(src, encoding, output string) error {
-	srcFile, err := os.Open(src)
+// verify parses src and prints the violations of the invariants of its declarations tree, if any. It reports whether
+// the tree is valid.
+func verify(src, encoding string) bool {
+	srcBytes, err := ioutil.ReadFile(src)
 	if err != nil {
-		return err
+		log.Fatalf("error reading %s: %s", src, err)
+	}
+	file, err := smgo.Parse(bytes.NewReader(srcBytes), encoding)
+	if err != nil {
+		log.Fatalf("error parsing %s: %s", src, err)
 	}
-	defer srcFile.Close()
+	decoded, err := smgo.Decode(bytes.NewReader(srcBytes), encoding)
+	if err != nil {
+		log.Fatalf("error decoding %s: %s", src, err)
+	}
+	violations := smgo.Validate(file, decoded)
+	for _, v := range violations {
+		fmt.Printf("%s: %s\n", src, v)
+	}
+	return len(violations) == 0
+}
 
-	dtFile, err := smgo.Parse(srcFile, encoding)
+func parse(src, encoding, output string) (err error) {
+	// a failure with one file must not stop the shell
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("internal error: %v", r)
+		}
+	}()
+
+	if *gitAttributesMode != "" {
+		encoding = checkGitAttributes(src, encoding, *gitAttributesMode == "override", log.Default())
+	}
+
+	var opts []smgo.Option
+	if *generatedMode == "coarse" {
+		opts = append(opts, smgo.WithGeneratedCode(smgo.GeneratedCoarse))
+	}
+	if *importSections {
+		opts = append(opts, smgo.WithImportSections(true))
+	}
+	dtFile, err := smgo.NewParser(opts...).ParseFile(context.Background(), src, encoding)
 	if err != nil {
 		return err
 	}
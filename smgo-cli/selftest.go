@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"unicode/utf16"
+
+	"github.com/jriquelme/SemanticMergeGO/smgo"
+)
+
+// maxExamples is the number of files listed for every cause of failure.
+const maxExamples = 5
+
+// goRootSrc returns the directory with the sources of the standard library.
+func goRootSrc() string {
+	goRoot := os.Getenv("GOROOT")
+	if goRoot == "" {
+		goRoot = runtime.GOROOT()
+	}
+	return filepath.Join(goRoot, "src")
+}
+
+// selfTestFailure is a file failing the self test.
+type selfTestFailure struct {
+	Path   string
+	Cause  string
+	Detail string
+}
+
+// syntaxErrors is the cause of the files with syntax errors (like the invalid code of testdata directories) whose
+// trees are fine: they're listed, but they don't fail the self test.
+const syntaxErrors = "syntax errors"
+
+// selfTest parses every .go file under root, checking the invariants of the trees and that their spans give back the
+// source. It writes a summary of the failures, grouped by cause, to w and reports whether all the files passed.
+func selfTest(root string, w io.Writer) (bool, error) {
+	var failures []selfTestFailure
+	files, withSyntaxErrors := 0, 0
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".go" {
+			return nil
+		}
+		files++
+		if f := checkFile(path); f != nil {
+			failures = append(failures, *f)
+			if f.Cause == syntaxErrors {
+				withSyntaxErrors++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Fprintf(w, "checked %d files under %s: %d failures, %d with syntax errors\n", files, root,
+		len(failures)-withSyntaxErrors, withSyntaxErrors)
+	byCause := make(map[string][]selfTestFailure)
+	var causes []string
+	for _, f := range failures {
+		if _, ok := byCause[f.Cause]; !ok {
+			causes = append(causes, f.Cause)
+		}
+		byCause[f.Cause] = append(byCause[f.Cause], f)
+	}
+	sort.Slice(causes, func(i, j int) bool {
+		return len(byCause[causes[i]]) > len(byCause[causes[j]])
+	})
+	for _, cause := range causes {
+		fmt.Fprintf(w, "%s (%d files)\n", cause, len(byCause[cause]))
+		for i, f := range byCause[cause] {
+			if i == maxExamples {
+				fmt.Fprintf(w, "\t...\n")
+				break
+			}
+			fmt.Fprintf(w, "\t%s: %s\n", f.Path, f.Detail)
+		}
+	}
+	return len(failures) == withSyntaxErrors, nil
+}
+
+// numbers matches the numbers in messages, replaced to group them by cause.
+var numbers = regexp.MustCompile(`[0-9]+`)
+
+// checkFile parses path and checks the resulting tree, returning nil if it's fine.
+func checkFile(path string) *selfTestFailure {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return &selfTestFailure{path, "read error", err.Error()}
+	}
+	file, err := smgo.Parse(bytes.NewReader(src), "UTF-8")
+	if err != nil {
+		cause := "parse error"
+		if _, ok := err.(*smgo.InternalError); ok {
+			cause = "internal error"
+		}
+		return &selfTestFailure{path, cause, err.Error()}
+	}
+	// the spans are in characters of the source as decoded
+	src, err = smgo.Decode(bytes.NewReader(src), "UTF-8")
+	if err != nil {
+		return &selfTestFailure{path, "parse error", err.Error()}
+	}
+	if violations := smgo.Validate(file, src); len(violations) > 0 {
+		return &selfTestFailure{path, "invalid tree: " + numbers.ReplaceAllString(violations[0].Message, "N"),
+			violations[0].String()}
+	}
+	if reassembled := reassemble(file, src); !bytes.Equal(reassembled, src) {
+		return &selfTestFailure{path, "reassembled source differs", fmt.Sprintf("%d bytes, expected %d",
+			len(reassembled), len(src))}
+	}
+	if len(file.ParsingErrors) > 0 {
+		e := file.ParsingErrors[0]
+		return &selfTestFailure{path, syntaxErrors, fmt.Sprintf("%s %s", e.Location, e.Message)}
+	}
+	return nil
+}
+
+// reassemble concatenates the text of the spans of file, in characters of src. The tree must be valid.
+func reassemble(file *smgo.File, src []byte) []byte {
+	units := utf16.Encode([]rune(string(src)))
+	var out []uint16
+	add := func(s smgo.RuneSpan) {
+		out = append(out, units[s.Start:s.End+1]...)
+	}
+	var walk func(nodes []smgo.Node)
+	walk = func(nodes []smgo.Node) {
+		for _, node := range nodes {
+			switch n := node.(type) {
+			case *smgo.Terminal:
+				add(n.Span)
+			case *smgo.Container:
+				add(n.HeaderSpan)
+				walk(n.Children)
+				add(n.FooterSpan)
+			}
+		}
+	}
+	walk(file.Children)
+	add(file.FooterSpan)
+	return []byte(string(utf16.Decode(out)))
+}
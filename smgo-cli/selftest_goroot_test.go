@@ -0,0 +1,21 @@
+//go:build goroot
+// +build goroot
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTestGoRoot(t *testing.T) {
+	var out bytes.Buffer
+	ok, err := selfTest(goRootSrc(), &out)
+	require.Nil(t, err)
+	t.Log(out.String())
+	if !ok {
+		t.Error("some files of GOROOT failed the self test")
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTest(t *testing.T) {
+	root, err := ioutil.TempDir("", "smgo-selftest")
+	require.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		"ok.go":             "package p\n\n// F does nothing.\nfunc F() {\n\t// nothing\n}\n",
+		"sub/broken.go":     "package p\n\nfunc F( {\n}\n",
+		"sub/notes.txt":     "not go",
+		"testdata/wrong.go": "not go",
+		"testdata/bad.go":   "package p\n\nvar x interface{ m() } = struct /* c */ {\n\tm func()\n}{}\n",
+	}
+	for name, content := range files {
+		name = filepath.Join(root, filepath.FromSlash(name))
+		require.Nil(t, os.MkdirAll(filepath.Dir(name), 0755))
+		require.Nil(t, ioutil.WriteFile(name, []byte(content), 0644))
+	}
+
+	var out bytes.Buffer
+	ok, err := selfTest(root, &out)
+	require.Nil(t, err)
+	// files with syntax errors are listed, but they don't fail if their trees are fine
+	assert.True(t, ok)
+	assert.Equal(t, "checked 4 files under "+root+": 0 failures, 2 with syntax errors\n"+
+		"syntax errors (2 files)\n"+
+		"\t"+filepath.Join(root, "sub", "broken.go")+": [L:3 C:8] expected ')', found '{'\n"+
+		"\t"+filepath.Join(root, "testdata", "wrong.go")+": [L:1 C:0] expected 'package', found not\n", out.String())
+}
+
+func TestCheckFile(t *testing.T) {
+	src := []byte("package p\n\n// Año 😀\ntype T struct {\n\tA int\n}\n\n// end\n")
+	assert.Nil(t, checkFile("testdata/simple_func.go"))
+
+	dir, err := ioutil.TempDir("", "smgo-reassemble")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "reassemble.go")
+	require.Nil(t, ioutil.WriteFile(path, src, 0644))
+	assert.Nil(t, checkFile(path))
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShell(t *testing.T) {
+	dir, err := ioutil.TempDir("", "smgo-shell")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// a failure is reported as KO, and the shell goes on with the next file
+	in := strings.Join([]string{
+		filepath.Join(dir, "missing.go"), "UTF-8", filepath.Join(dir, "missing.yaml"),
+		"testdata/simple_func.go", "UTF-8", filepath.Join(dir, "simple_func.yaml"),
+		"end",
+	}, "\n") + "\n"
+	var out bytes.Buffer
+	shell(filepath.Join(dir, "flag-file"), strings.NewReader(in), &out)
+	assert.Equal(t, "KO\nOK\n", out.String())
+
+	flag, err := ioutil.ReadFile(filepath.Join(dir, "flag-file"))
+	require.Nil(t, err)
+	assert.Equal(t, []byte{1}, flag)
+	output, err := ioutil.ReadFile(filepath.Join(dir, "simple_func.yaml"))
+	require.Nil(t, err)
+	expected, err := ioutil.ReadFile("testdata/simple_func.yaml")
+	require.Nil(t, err)
+	assert.Equal(t, string(expected), string(output))
+}
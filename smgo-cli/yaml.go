@@ -98,8 +98,18 @@ func toType(t smgo.NodeType) string {
 		return "Package"
 	case smgo.FunctionNode:
 		return "Function"
+	case smgo.MethodNode:
+		return "Method"
 	case smgo.FieldNode:
 		return "Field"
+	case smgo.EmbeddedFieldNode:
+		return "EmbeddedField"
+	case smgo.EmbeddedInterfaceNode:
+		return "EmbeddedInterface"
+	case smgo.MethodSpecNode:
+		return "MethodSpec"
+	case smgo.TypeSetNode:
+		return "TypeSet"
 	case smgo.ImportNode:
 		return "Import"
 	case smgo.ConstNode:
@@ -108,10 +118,26 @@ func toType(t smgo.NodeType) string {
 		return "Variable"
 	case smgo.TypeNode:
 		return "Type"
+	case smgo.AliasNode:
+		return "Alias"
 	case smgo.StructNode:
 		return "Struct"
 	case smgo.InterfaceNode:
 		return "Interface"
+	case smgo.UnparsedNode:
+		return "Unparsed"
+	case smgo.BuildConstraintNode:
+		return "BuildConstraint"
+	case smgo.DirectiveNode:
+		return "Directive"
+	case smgo.CgoPreambleNode:
+		return "CgoPreamble"
+	case smgo.CgoDeclNode:
+		return "CgoDecl"
+	case smgo.GeneratedNode:
+		return "Generated"
+	case smgo.ImportSectionNode:
+		return "ImportSection"
 	default:
 		return "Unknown"
 	}
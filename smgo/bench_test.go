@@ -0,0 +1,55 @@
+package smgo_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/jriquelme/SemanticMergeGO/smgo"
+)
+
+// syntheticSrc returns a file with n declarations of every kind, most of them commented, like the big generated
+// files.
+func syntheticSrc(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("// Package synthetic is generated.\npackage synthetic\n\nimport (\n\t\"fmt\" // fmt\n\t\"io\"\n)\n\n")
+	for i := 0; i < n; i++ {
+		switch i % 5 {
+		case 0:
+			fmt.Fprintf(&buf, "// F%d does something.\nfunc F%d(w io.Writer) {\n\t// print it\n\tfmt.Fprintln(w, %d)\n}\n\n", i, i, i)
+		case 1:
+			fmt.Fprintf(&buf, "// T%d is a type.\ntype T%d struct {\n\t// A is a field.\n\tA int // a\n\tB string\n}\n\n", i, i)
+		case 2:
+			fmt.Fprintf(&buf, "const (\n\t// C%d is a constant.\n\tC%d = %d // c\n\tD%d = %d\n)\n\n", i, i, i, i, i)
+		case 3:
+			fmt.Fprintf(&buf, "// free-floating comment %d\n\nvar V%d = %d // v\n\n", i, i, i)
+		case 4:
+			fmt.Fprintf(&buf, "/*\n * block comment %d\n */\n\n// I%d is an interface.\ntype I%d interface {\n\tM() // m\n}\n\n", i, i, i)
+		}
+	}
+	return buf.Bytes()
+}
+
+func benchmarkParse(b *testing.B, n int) {
+	src := syntheticSrc(n)
+	b.SetBytes(int64(len(src)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := smgo.Parse(bytes.NewReader(src), "UTF-8")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParse1k(b *testing.B) {
+	benchmarkParse(b, 1000)
+}
+
+func BenchmarkParse10k(b *testing.B) {
+	benchmarkParse(b, 10000)
+}
+
+func BenchmarkParse20k(b *testing.B) {
+	benchmarkParse(b, 20000)
+}
@@ -1,13 +1,14 @@
 package smgo
 
 import (
+	"bytes"
 	"go/token"
+	"io"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/pkg/errors"
 )
 
-var PrintBlocks bool
-
 type blockType int
 
 //go:generate stringer -type=blockType
@@ -53,16 +54,20 @@ func addBlocksFrom(parent parentNode, blocks *[]block) {
 	}
 }
 
-func fixBlockBoundaries(fileSet *token.FileSet, file *File, src []byte) error {
+// fixBlockBoundaries extends the spans of the nodes of file to tile src. The blocks before and after fixing them are
+// printed to debug, if not nil.
+func fixBlockBoundaries(fileSet *token.FileSet, file *File, src []byte, debug io.Writer) error {
 	var blocks []block
 	addBlocksFrom(file, &blocks)
 
-	if PrintBlocks {
-		printBlocks("original blocks", blocks)
+	if debug != nil {
+		printBlocks(debug, "original blocks", blocks)
 	}
 
 	file.LocationSpan.Start.Column = 0
 
+	// spans are inclusive, the last node of a file without a trailing newline ends at the last byte
+	last := len(src) - 1
 	offset := 0
 	for i := 0; i < len(blocks); i++ {
 		b := blocks[i]
@@ -70,32 +75,60 @@ func fixBlockBoundaries(fileSet *token.FileSet, file *File, src []byte) error {
 		case nodeBlock:
 			n := b.Terminal()
 			n.Span.Start = offset
-			newPos := fileSet.Position(token.Pos(n.Span.Start + 1))
+			if n.Span.End > last {
+				n.Span.End = last
+			}
+			if end := lineTerminatorEnd(src, n.Span.End); end != -1 {
+				// include the '\n' of a "\r\n"
+				n.Span.End = end
+			}
+			newPos := fileSet.PositionFor(token.Pos(n.Span.Start+1), false)
 			n.LocationSpan.Start.Line = newPos.Line
 			n.LocationSpan.Start.Column = newPos.Column - 1
 			offset = n.Span.End + 1
 		case containerHeader:
 			n := b.Container()
 			n.HeaderSpan.Start = offset
-			newPos := fileSet.Position(token.Pos(n.HeaderSpan.Start + 1))
+			newPos := fileSet.PositionFor(token.Pos(n.HeaderSpan.Start+1), false)
 			n.LocationSpan.Start.Line = newPos.Line
 			n.LocationSpan.Start.Column = newPos.Column - 1
-			if (src[n.HeaderSpan.End] == '(' || src[n.HeaderSpan.End] == '{') && src[n.HeaderSpan.End+1] == '\n' {
-				n.HeaderSpan.End++
+			if n.HeaderSpan.End > last {
+				return errors.Errorf("header of %s ends beyond the end of src (offset %d)", n.Name, n.HeaderSpan.End)
+			}
+			if src[n.HeaderSpan.End] == '(' || src[n.HeaderSpan.End] == '{' {
+				if end := lineTerminatorEnd(src, n.HeaderSpan.End+1); end != -1 {
+					n.HeaderSpan.End = end
+				}
+			} else if end := lineTerminatorEnd(src, n.HeaderSpan.End); end != -1 {
+				n.HeaderSpan.End = end
 			}
 			offset = n.HeaderSpan.End + 1
 		case containerFooter:
 			n := b.Container()
 			n.FooterSpan.Start = offset
-			if (src[n.FooterSpan.End] == ')' || src[n.FooterSpan.End] == '}') && src[n.FooterSpan.End+1] == '\n' {
-				n.FooterSpan.End++
+			if n.FooterSpan.End > last {
+				n.FooterSpan.End = last
+			}
+			// the location ends at the start of the line terminator after the closing token
+			locationEnd := n.FooterSpan.End
+			if src[n.FooterSpan.End] == ')' || src[n.FooterSpan.End] == '}' {
+				if end := lineTerminatorEnd(src, n.FooterSpan.End+1); end != -1 {
+					locationEnd = n.FooterSpan.End + 1
+					n.FooterSpan.End = end
+				}
+			} else if end := lineTerminatorEnd(src, n.FooterSpan.End); end != -1 {
+				n.FooterSpan.End = end
+			}
+			if n.FooterSpan.End == last && lineTerminatorEnd(src, last) == -1 {
+				// no trailing newline, the location ends right after the closing token
+				locationEnd = last + 1
 			}
-			newPos := fileSet.Position(token.Pos(n.FooterSpan.End + 1))
+			newPos := fileSet.PositionFor(token.Pos(locationEnd+1), false)
 			n.LocationSpan.End.Line = newPos.Line
 			n.LocationSpan.End.Column = newPos.Column
 			offset = n.FooterSpan.End + 1
 		default:
-			panic("impossibru!")
+			return errors.Errorf("unknown block type %s", b.Type)
 		}
 	}
 
@@ -104,12 +137,30 @@ func fixBlockBoundaries(fileSet *token.FileSet, file *File, src []byte) error {
 		file.FooterSpan = RuneSpan{offset, len(src) - 1}
 	}
 
-	if PrintBlocks {
-		printBlocks("fixed blocks", blocks)
+	if debug != nil {
+		printBlocks(debug, "fixed blocks", blocks)
 	}
 	return nil
 }
 
+// lineTerminatorEnd returns the offset of the last byte of the line terminator ("\n", "\r\n" or a lone '\r')
+// starting at offset, or -1 if there isn't one.
+func lineTerminatorEnd(src []byte, offset int) int {
+	if offset >= len(src) {
+		return -1
+	}
+	switch src[offset] {
+	case '\n':
+		return offset
+	case '\r':
+		if offset+1 < len(src) && src[offset+1] == '\n' {
+			return offset + 1
+		}
+		return offset
+	}
+	return -1
+}
+
 type debugBlock struct {
 	BlockType    blockType
 	Name         string
@@ -117,7 +168,7 @@ type debugBlock struct {
 	Span         RuneSpan
 }
 
-func printBlocks(title string, blocks []block) {
+func printBlocks(w io.Writer, title string, blocks []block) {
 	debugBlocks := make([]debugBlock, 0, len(blocks))
 	for _, b := range blocks {
 		switch b.Type {
@@ -146,7 +197,10 @@ func printBlocks(title string, blocks []block) {
 			panic("impossibru!")
 		}
 	}
-	spew.Printf("----------%s----------\n", title)
-	spew.Dump(debugBlocks)
-	spew.Printf("--------------------\n")
+	// written at once, so the output of concurrent calls doesn't interleave
+	var buf bytes.Buffer
+	spew.Fprintf(&buf, "----------%s----------\n", title)
+	spew.Fdump(&buf, debugBlocks)
+	spew.Fprintf(&buf, "--------------------\n")
+	w.Write(buf.Bytes())
 }
@@ -0,0 +1,261 @@
+package smgo
+
+import (
+	"bytes"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// cgoPreamble returns the preamble of an import "C" spec, the comment right before it, or nil if n doesn't import
+// "C" or hasn't a preamble. Like cgo does, the doc comment of the declaration is used for a single spec, unless it's
+// a group: the comment is then before the group itself.
+func cgoPreamble(gd *ast.GenDecl, n *ast.ImportSpec) *ast.CommentGroup {
+	if n.Path.Value != `"C"` {
+		return nil
+	}
+	if n.Doc != nil {
+		return n.Doc
+	}
+	if len(gd.Specs) == 1 && !gd.Lparen.IsValid() {
+		return gd.Doc
+	}
+	return nil
+}
+
+// createCgoPreamble creates the container of an import "C" spec, with the C declarations of its preamble as
+// children. The header is the opening of the preamble comment, the footer goes from the end of the last C
+// declaration to the end of the import.
+func (v *visitor) createCgoPreamble(gd *ast.GenDecl, n *ast.ImportSpec, preamble *ast.CommentGroup) *Container {
+	v.Comments.consume(gd.Doc)
+	v.Comments.consume(n.Doc)
+	end := n.End()
+	if n.Comment != nil {
+		end = n.Comment.End()
+		v.Comments.consume(n.Comment)
+	}
+	tokenFile := v.FileSet.File(preamble.Pos())
+	start := tokenFile.Offset(preamble.Pos())
+	// "/*" or "//", and the line terminator if nothing follows it
+	headerEnd := start + 1
+	if lineTerminatorEnd(v.src, start+2) != -1 {
+		headerEnd = start + 2
+	}
+	c := &Container{
+		Type:         CgoPreambleNode,
+		Name:         "C",
+		LocationSpan: locationSpanFromPositions(v.FileSet, preamble.Pos(), end),
+		HeaderSpan:   RuneSpan{start, headerEnd},
+		FooterSpan:   runeSpanFromPositions(v.FileSet, end, end),
+	}
+	for _, d := range scanCgoDecls(v.src, tokenFile, preamble) {
+		c.AddNode(&Terminal{
+			Type:         CgoDeclNode,
+			Name:         d.Name,
+			LocationSpan: locationSpanFromPositions(v.FileSet, tokenFile.Pos(d.Start), tokenFile.Pos(d.End)),
+			Span:         RuneSpan{d.Start, d.End},
+		})
+	}
+	return c
+}
+
+// cgoDecl is a C declaration of a preamble: the offsets of its first char and of the char after it, and its name.
+type cgoDecl struct {
+	Start int
+	End   int
+	Name  string
+}
+
+// cgoLine is a line of the text of a preamble, without comment markers.
+type cgoLine struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// preambleLines returns the lines of the text of preamble, read from src: the comment positions from go/ast can't be
+// trusted for the end of /*-style comments, the scanner drops their '\r'.
+func preambleLines(src []byte, tokenFile *token.File, preamble *ast.CommentGroup) []cgoLine {
+	var lines []cgoLine
+	add := func(start, end int) {
+		if end > start && src[end-1] == '\r' {
+			end--
+		}
+		lines = append(lines, cgoLine{start, end, string(src[start:end])})
+	}
+	for _, c := range preamble.List {
+		start := tokenFile.Offset(c.Pos())
+		if strings.HasPrefix(c.Text, "//") {
+			end := bytes.IndexByte(src[start:], '\n')
+			if end == -1 {
+				end = len(src)
+			} else {
+				end += start
+			}
+			add(start+2, end)
+			continue
+		}
+		end := bytes.Index(src[start+2:], []byte("*/"))
+		if end == -1 {
+			end = len(src)
+		} else {
+			end += start + 2
+		}
+		lineStart := start + 2
+		for i := lineStart; i < end; i++ {
+			if src[i] == '\n' {
+				add(lineStart, i)
+				lineStart = i + 1
+			}
+		}
+		add(lineStart, end)
+	}
+	return lines
+}
+
+// scanCgoDecls finds the C declarations in the text of preamble with a lightweight scan: every preprocessor line
+// (#include, #define, #cgo...) is a declaration, and so is every run of lines up to a ';' or '}' closing all the
+// braces opened. Blank lines and comments before a declaration are part of it.
+func scanCgoDecls(src []byte, tokenFile *token.File, preamble *ast.CommentGroup) []cgoDecl {
+	var decls []cgoDecl
+	var text []string
+	start, end, depth, continued := -1, 0, 0, false
+	for _, line := range preambleLines(src, tokenFile, preamble) {
+		t := strings.TrimSpace(line.Text)
+		// declarations end right after their text, not including the "*/" closing the preamble
+		lineEnd := line.Start + len(strings.TrimRight(line.Text, " \t"))
+		switch {
+		case continued:
+			// continuation of a preprocessor line
+			decls[len(decls)-1].End = lineEnd
+			continued = strings.HasSuffix(t, "\\")
+			continue
+		case len(text) == 0 && (t == "" || strings.HasPrefix(t, "//") || strings.HasPrefix(t, "/*")):
+			if start == -1 {
+				start = line.Start
+			}
+			continue
+		case len(text) == 0 && strings.HasPrefix(t, "#"):
+			if start == -1 {
+				start = line.Start
+			}
+			decls = append(decls, cgoDecl{start, lineEnd, cPreprocessorName(t)})
+			start, continued = -1, strings.HasSuffix(t, "\\")
+			continue
+		}
+		if start == -1 {
+			start = line.Start
+		}
+		text = append(text, t)
+		end = lineEnd
+		depth += cBraces(t)
+		if depth <= 0 && (strings.HasSuffix(t, ";") || strings.HasSuffix(t, "}")) {
+			decls = append(decls, cgoDecl{start, end, cDeclName(strings.Join(text, " "))})
+			text, start, depth = nil, -1, 0
+		}
+	}
+	if len(text) > 0 {
+		// unterminated declaration
+		decls = append(decls, cgoDecl{start, end, cDeclName(strings.Join(text, " "))})
+	}
+	return decls
+}
+
+// cPreprocessorName returns the name of a preprocessor line: the directive and its first argument, like
+// "#include <stdio.h>" or "#ifdef DEBUG", only the macro name for #define and the flags variable for #cgo.
+func cPreprocessorName(line string) string {
+	fields := strings.Fields(strings.TrimPrefix(line, "#"))
+	if len(fields) == 0 {
+		return "#"
+	}
+	directive := fields[0]
+	switch {
+	case directive == "cgo":
+		// #cgo [GOOS/GOARCH...] FLAGS: values
+		if i := strings.Index(line, ":"); i != -1 {
+			return strings.Join(strings.Fields(line[:i]), " ")
+		}
+	case directive == "define" && len(fields) > 1:
+		name := fields[1]
+		if i := strings.Index(name, "("); i != -1 {
+			name = name[:i]
+		}
+		return "#define " + name
+	case len(fields) > 1:
+		return "#" + directive + " " + fields[1]
+	}
+	return "#" + directive
+}
+
+// cBraces returns the number of braces opened minus the number of braces closed in line, skipping string and char
+// literals.
+func cBraces(line string) int {
+	n := 0
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '{':
+			n++
+		case c == '}':
+			n--
+		}
+	}
+	return n
+}
+
+// cDeclName returns the name declared by a C declaration: the function in "static int add(int a, int b) {...}", the
+// variable in "int counter = 0;", the tag in "struct point {...};" or the type in "typedef struct {...} point;".
+func cDeclName(decl string) string {
+	s := decl
+	if strings.HasPrefix(s, "typedef") {
+		// the name follows the body, if any
+		if i := strings.LastIndex(s, "}"); i != -1 {
+			s = s[i+1:]
+		}
+	} else if i := strings.IndexAny(s, "{=;"); i != -1 {
+		s = s[:i]
+	}
+	s = strings.TrimSuffix(strings.TrimSpace(s), ";")
+	if i := strings.Index(s, "(*"); i != -1 {
+		// function pointer, int (*name)(int)
+		s = s[i+2:]
+		if j := strings.Index(s, ")"); j != -1 {
+			s = s[:j]
+		}
+	} else if i := strings.Index(s, "("); i != -1 {
+		// function, the name is right before the parameters
+		s = s[:i]
+	}
+	if i := strings.Index(s, "["); i != -1 {
+		s = s[:i]
+	}
+	if name := lastIdentifier(s); name != "" {
+		return name
+	}
+	return decl
+}
+
+// lastIdentifier returns the last C identifier in s, empty if there isn't any.
+func lastIdentifier(s string) string {
+	isIdent := func(c byte) bool {
+		return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+	}
+	end := len(s)
+	for end > 0 && !isIdent(s[end-1]) {
+		end--
+	}
+	start := end
+	for start > 0 && isIdent(s[start-1]) {
+		start--
+	}
+	return s[start:end]
+}
@@ -1,6 +1,7 @@
 package smgo_test
 
 import (
+	"context"
 	"os"
 	"strings"
 	"testing"
@@ -13,9 +14,7 @@ import (
 
 func TestParseCommentCases(t *testing.T) {
 	t.Parallel()
-	if testing.Verbose() {
-		smgo.PrintBlocks = true
-	}
+	parser := newTestParser()
 
 	cases := []struct {
 		Src          string
@@ -56,7 +55,7 @@ func TestParseCommentCases(t *testing.T) {
 					},
 					&smgo.Container{
 						Type:         smgo.ConstNode,
-						Name:         "const",
+						Name:         "const#2",
 						LocationSpan: newLocationSpan(10, 0, 12, 9),
 						HeaderSpan:   smgo.RuneSpan{94, 116},
 						FooterSpan:   smgo.RuneSpan{117, 118},
@@ -72,6 +71,137 @@ func TestParseCommentCases(t *testing.T) {
 				ParsingErrors: nil,
 			},
 		},
+		{
+			Src: "comment_directives.go",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 34, 35),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.Comment,
+						Name:         "Copyright ...",
+						LocationSpan: newLocationSpan(1, 0, 1, 21),
+						Span:         smgo.RuneSpan{0, 20},
+					},
+					&smgo.Terminal{
+						Type:         smgo.BuildConstraintNode,
+						Name:         "linux && !386",
+						LocationSpan: newLocationSpan(2, 0, 4, 21),
+						Span:         smgo.RuneSpan{21, 67},
+					},
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "commentdirectives",
+						LocationSpan: newLocationSpan(5, 0, 7, 26),
+						Span:         smgo.RuneSpan{68, 139},
+					},
+					&smgo.Container{
+						Type:         smgo.ImportNode,
+						Name:         "import",
+						LocationSpan: newLocationSpan(8, 0, 12, 2),
+						HeaderSpan:   smgo.RuneSpan{140, 149},
+						FooterSpan:   smgo.RuneSpan{171, 172},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.ImportNode,
+								Name:         "_ embed",
+								LocationSpan: newLocationSpan(10, 0, 10, 11),
+								Span:         smgo.RuneSpan{150, 160},
+								LocalName:    "_",
+							},
+							&smgo.Terminal{
+								Type:         smgo.ImportNode,
+								Name:         "unsafe",
+								LocationSpan: newLocationSpan(11, 0, 11, 10),
+								Span:         smgo.RuneSpan{161, 170},
+							},
+						},
+					},
+					&smgo.Terminal{
+						Type:         smgo.DirectiveNode,
+						Name:         "go:generate",
+						LocationSpan: newLocationSpan(13, 0, 14, 34),
+						Span:         smgo.RuneSpan{173, 207},
+					},
+					&smgo.Terminal{
+						Type:         smgo.TypeNode,
+						Name:         "Kind",
+						LocationSpan: newLocationSpan(15, 0, 17, 14),
+						Span:         smgo.RuneSpan{208, 241},
+					},
+					&smgo.Container{
+						Type:         smgo.DirectiveNode,
+						Name:         "src",
+						LocationSpan: newLocationSpan(18, 0, 20, 15),
+						HeaderSpan:   smgo.RuneSpan{242, 275},
+						FooterSpan:   smgo.RuneSpan{291, 290},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.VarNode,
+								Name:         "src",
+								LocationSpan: newLocationSpan(20, 0, 20, 15),
+								Span:         smgo.RuneSpan{276, 290},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.DirectiveNode,
+						Name:         "now",
+						LocationSpan: newLocationSpan(21, 0, 26, 33),
+						HeaderSpan:   smgo.RuneSpan{291, 360},
+						FooterSpan:   smgo.RuneSpan{394, 393},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.FunctionNode,
+								Name:         "now",
+								LocationSpan: newLocationSpan(26, 0, 26, 33),
+								Span:         smgo.RuneSpan{361, 393},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.DirectiveNode,
+						Name:         "T",
+						LocationSpan: newLocationSpan(27, 0, 31, 2),
+						HeaderSpan:   smgo.RuneSpan{394, 409},
+						FooterSpan:   smgo.RuneSpan{446, 445},
+						Children: []smgo.Node{
+							&smgo.Container{
+								Type:         smgo.StructNode,
+								Name:         "T",
+								LocationSpan: newLocationSpan(29, 0, 31, 2),
+								HeaderSpan:   smgo.RuneSpan{410, 425},
+								FooterSpan:   smgo.RuneSpan{444, 445},
+								Children: []smgo.Node{
+									&smgo.Terminal{
+										Type:         smgo.FieldNode,
+										Name:         "A",
+										LocationSpan: newLocationSpan(30, 0, 30, 18),
+										Span:         smgo.RuneSpan{426, 443},
+									},
+								},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.DirectiveNode,
+						Name:         "linked",
+						LocationSpan: newLocationSpan(32, 0, 34, 35),
+						HeaderSpan:   smgo.RuneSpan{446, 467},
+						FooterSpan:   smgo.RuneSpan{503, 502},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.VarNode,
+								Name:         "linked",
+								LocationSpan: newLocationSpan(34, 0, 34, 35),
+								Span:         smgo.RuneSpan{468, 502},
+							},
+						},
+					},
+				},
+				ParsingErrors: nil,
+			},
+		},
 		{
 			Src: "comment_import.go_src",
 			ExpectedFile: &smgo.File{
@@ -89,7 +219,7 @@ func TestParseCommentCases(t *testing.T) {
 						Name:         "import",
 						LocationSpan: newLocationSpan(2, 0, 13, 2),
 						HeaderSpan:   smgo.RuneSpan{22, 63},
-						FooterSpan:   smgo.RuneSpan{147, 148},
+						FooterSpan:   smgo.RuneSpan{161, 162},
 						Children: []smgo.Node{
 							&smgo.Terminal{
 								Type:         smgo.ImportNode,
@@ -100,36 +230,36 @@ func TestParseCommentCases(t *testing.T) {
 							&smgo.Terminal{
 								Type:         smgo.ImportNode,
 								Name:         "io/ioutil",
-								LocationSpan: newLocationSpan(8, 0, 8, 13),
-								Span:         smgo.RuneSpan{77, 89},
+								LocationSpan: newLocationSpan(8, 0, 8, 27),
+								Span:         smgo.RuneSpan{77, 103},
 							},
 							&smgo.Terminal{
 								Type:         smgo.ImportNode,
 								Name:         "github.com/pkg/errors",
 								LocationSpan: newLocationSpan(9, 0, 12, 25),
-								Span:         smgo.RuneSpan{90, 146},
+								Span:         smgo.RuneSpan{104, 160},
 							},
 						},
 					},
 					&smgo.Container{
 						Type:         smgo.ImportNode,
-						Name:         "import",
+						Name:         "import#2",
 						LocationSpan: newLocationSpan(14, 0, 16, 10),
-						HeaderSpan:   smgo.RuneSpan{149, 179},
-						FooterSpan:   smgo.RuneSpan{180, 181},
+						HeaderSpan:   smgo.RuneSpan{163, 193},
+						FooterSpan:   smgo.RuneSpan{194, 195},
 						Children:     nil,
 					},
 					&smgo.Terminal{
 						Type:         smgo.ImportNode,
 						Name:         "fmt",
 						LocationSpan: newLocationSpan(17, 0, 18, 13),
-						Span:         smgo.RuneSpan{182, 195},
+						Span:         smgo.RuneSpan{196, 209},
 					},
 					&smgo.Terminal{
 						Type:         smgo.ImportNode,
 						Name:         "strings",
 						LocationSpan: newLocationSpan(19, 0, 21, 17),
-						Span:         smgo.RuneSpan{196, 228},
+						Span:         smgo.RuneSpan{210, 242},
 					},
 				},
 				ParsingErrors: nil,
@@ -189,7 +319,7 @@ func TestParseCommentCases(t *testing.T) {
 								Span:         smgo.RuneSpan{52, 90},
 							},
 							&smgo.Terminal{
-								Type:         smgo.TypeNode,
+								Type:         smgo.AliasNode,
 								Name:         "StringAlias",
 								LocationSpan: newLocationSpan(7, 0, 9, 25),
 								Span:         smgo.RuneSpan{91, 129},
@@ -235,13 +365,13 @@ func TestParseCommentCases(t *testing.T) {
 								FooterSpan:   smgo.RuneSpan{414, 448},
 								Children: []smgo.Node{
 									&smgo.Terminal{
-										Type:         smgo.FieldNode,
+										Type:         smgo.MethodSpecNode,
 										Name:         "Area",
 										LocationSpan: newLocationSpan(25, 0, 26, 24),
 										Span:         smgo.RuneSpan{336, 369},
 									},
 									&smgo.Terminal{
-										Type:         smgo.FieldNode,
+										Type:         smgo.MethodSpecNode,
 										Name:         "Perimeter",
 										LocationSpan: newLocationSpan(27, 0, 28, 29),
 										Span:         smgo.RuneSpan{370, 413},
@@ -252,7 +382,7 @@ func TestParseCommentCases(t *testing.T) {
 					},
 					&smgo.Container{
 						Type:         smgo.TypeNode,
-						Name:         "type",
+						Name:         "type#2",
 						LocationSpan: newLocationSpan(36, 0, 38, 24),
 						HeaderSpan:   smgo.RuneSpan{489, 515},
 						FooterSpan:   smgo.RuneSpan{516, 533},
@@ -297,7 +427,7 @@ func TestParseCommentCases(t *testing.T) {
 			require.Nil(t, err)
 			defer srcFile.Close()
 
-			file, err := smgo.Parse(srcFile, "UTF-8")
+			file, err := parser.ParseReader(context.Background(), srcFile, "UTF-8")
 			assert.NotNil(t, file)
 			assert.Nil(t, err)
 
@@ -0,0 +1,76 @@
+package smgo
+
+import (
+	"bytes"
+	"go/ast"
+	"go/token"
+	"sort"
+)
+
+// commentCursor hands out the comment groups of a file in order. A group is either free-floating, returned by before
+// when the cursor moves past it, or consumed as part of a node (doc and line comments, comments inside the span of a
+// node) and skipped. The declarations are visited in order, so the cursor only moves forward and the whole file is
+// processed in linear time.
+type commentCursor struct {
+	groups []*ast.CommentGroup
+	// starts and ends are the offsets of groups, ends[i] is the offset of the char after groups[i]
+	starts   []int
+	ends     []int
+	consumed map[*ast.CommentGroup]struct{}
+	// next is the index of the first group the cursor hasn't moved past yet
+	next int
+}
+
+// newCommentCursor returns a cursor over comments, sorted by position like ast.File.Comments.
+func newCommentCursor(fset *token.FileSet, comments []*ast.CommentGroup) *commentCursor {
+	c := &commentCursor{
+		groups:   comments,
+		starts:   make([]int, len(comments)),
+		ends:     make([]int, len(comments)),
+		consumed: make(map[*ast.CommentGroup]struct{}),
+	}
+	for i, cg := range comments {
+		c.starts[i] = fset.PositionFor(cg.Pos(), false).Offset
+		c.ends[i] = fset.PositionFor(cg.End(), false).Offset
+	}
+	return c
+}
+
+// consume marks cg, if any, as part of a node.
+func (c *commentCursor) consume(cg *ast.CommentGroup) {
+	if cg != nil {
+		c.consumed[cg] = struct{}{}
+	}
+}
+
+// consumeRange marks the groups starting in [start, end) as part of a node.
+func (c *commentCursor) consumeRange(start, end int) {
+	i := c.next + sort.SearchInts(c.starts[c.next:], start)
+	for ; i < len(c.groups) && c.starts[i] < end; i++ {
+		c.consumed[c.groups[i]] = struct{}{}
+	}
+}
+
+// before moves the cursor past the groups ending before offset, returning the free-floating ones.
+func (c *commentCursor) before(offset int) []*ast.CommentGroup {
+	var groups []*ast.CommentGroup
+	for ; c.next < len(c.groups) && c.ends[c.next] < offset; c.next++ {
+		if _, ok := c.consumed[c.groups[c.next]]; !ok {
+			groups = append(groups, c.groups[c.next])
+		}
+	}
+	return groups
+}
+
+// commentEnd returns the offset of the char after the comment group cg. The scanner drops the '\r' of /*-style
+// comments from their text, so their end is looked up in src.
+func commentEnd(fset *token.FileSet, src []byte, cg *ast.CommentGroup) int {
+	last := cg.List[len(cg.List)-1]
+	if last.Text[1] == '*' {
+		start := fset.PositionFor(last.Pos(), false).Offset
+		if i := bytes.Index(src[start+2:], []byte("*/")); i != -1 {
+			return start + 2 + i + 2
+		}
+	}
+	return fset.PositionFor(cg.End(), false).Offset
+}
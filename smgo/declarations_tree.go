@@ -7,6 +7,11 @@ type Node interface{}
 
 // File is the root of the declarations tree.
 type File struct {
+	// Generated is set for files with a "// Code generated ... DO NOT EDIT." comment before the package clause.
+	Generated bool
+	// AutoEncoding is the encoding chosen by the auto encoding: UTF-8, the encoding of the byte order mark or the
+	// fallback encoding. It's empty for other encodings.
+	AutoEncoding  string
 	LocationSpan  LocationSpan
 	FooterSpan    RuneSpan
 	Children      []Node
@@ -21,6 +26,66 @@ func (f *File) Nodes() []Node {
 	return f.Children
 }
 
+// FindNodes returns the nodes of the tree named name. Multi-name specs like var a, b, c int are found under each
+// one of their names too.
+func (f *File) FindNodes(name string) []Node {
+	var nodes []Node
+	findNodes(f, name, &nodes)
+	return nodes
+}
+
+func findNodes(parent parentNode, name string, nodes *[]Node) {
+	for _, child := range parent.Nodes() {
+		switch n := child.(type) {
+		case *Container:
+			if n.Name == name || containsName(n.Names, name) {
+				*nodes = append(*nodes, n)
+			}
+			findNodes(n, name, nodes)
+		case *Terminal:
+			if n.Name == name || containsName(n.Names, name) {
+				*nodes = append(*nodes, n)
+			}
+		}
+	}
+}
+
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// disambiguateNames renames siblings sharing type and name, like several func init(), appending their ordinal to
+// the name: init, init#2, init#3... Unrelated declarations don't affect the resulting names.
+func disambiguateNames(parent parentNode) {
+	type key struct {
+		Type NodeType
+		Name string
+	}
+	seen := make(map[key]int)
+	for _, child := range parent.Nodes() {
+		switch n := child.(type) {
+		case *Container:
+			k := key{n.Type, n.Name}
+			seen[k]++
+			if seen[k] > 1 {
+				n.Name = fmt.Sprintf("%s#%d", n.Name, seen[k])
+			}
+			disambiguateNames(n)
+		case *Terminal:
+			k := key{n.Type, n.Name}
+			seen[k]++
+			if seen[k] > 1 {
+				n.Name = fmt.Sprintf("%s#%d", n.Name, seen[k])
+			}
+		}
+	}
+}
+
 type NodeType int
 
 //go:generate stringer -type=NodeType
@@ -36,6 +101,19 @@ const (
 	StructNode
 	InterfaceNode
 	Comment
+	MethodNode
+	EmbeddedFieldNode
+	EmbeddedInterfaceNode
+	MethodSpecNode
+	TypeSetNode
+	AliasNode
+	UnparsedNode
+	BuildConstraintNode
+	DirectiveNode
+	CgoPreambleNode
+	CgoDeclNode
+	GeneratedNode
+	ImportSectionNode
 )
 
 type Container struct {
@@ -45,6 +123,8 @@ type Container struct {
 	HeaderSpan   RuneSpan
 	FooterSpan   RuneSpan
 	Children     []Node
+	// Names holds every identifier declared by a multi-name spec (var a, b struct{...}), nil otherwise.
+	Names []string
 }
 
 func (c *Container) AddNode(node Node) {
@@ -60,6 +140,10 @@ type Terminal struct {
 	Name         string
 	LocationSpan LocationSpan
 	Span         RuneSpan
+	// Names holds every identifier declared by a multi-name spec (var a, b, c int), nil otherwise.
+	Names []string
+	// LocalName is the name given to the package of an import (an alias, "." or "_"), empty otherwise.
+	LocalName string
 }
 
 type ParsingError struct {
@@ -0,0 +1,193 @@
+package smgo
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"strings"
+)
+
+// isDirective reports whether the comment text c is a directive, like //go:generate or //export (the same rules as
+// go/ast, which drops them from the text of doc comments).
+func isDirective(c string) bool {
+	c = strings.TrimPrefix(c, "//")
+	if strings.HasPrefix(c, "line ") || strings.HasPrefix(c, "extern ") || strings.HasPrefix(c, "export ") {
+		return true
+	}
+	// "//[a-z0-9]+:[a-z0-9]"
+	colon := strings.Index(c, ":")
+	if colon <= 0 || colon+1 >= len(c) {
+		return false
+	}
+	for i := 0; i <= colon+1; i++ {
+		if i == colon {
+			continue
+		}
+		b := c[i]
+		if !('a' <= b && b <= 'z' || '0' <= b && b <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isBuildConstraint reports whether the comment text c is a //go:build or // +build line.
+func isBuildConstraint(c string) bool {
+	return constraint.IsGoBuild(c) || constraint.IsPlusBuild(c)
+}
+
+// directiveName returns the name of a directive, without arguments: go:generate, go:embed, export...
+func directiveName(c string) string {
+	c = strings.TrimPrefix(c, "//")
+	if i := strings.IndexAny(c, " \t"); i != -1 {
+		return c[:i]
+	}
+	return c
+}
+
+// buildConstraintName returns the expression of the build constraint lines, in //go:build syntax. The //go:build
+// line wins over the // +build ones, which are combined like the go command does.
+func buildConstraintName(lines []*ast.Comment) string {
+	for _, c := range lines {
+		if constraint.IsGoBuild(c.Text) {
+			if expr, err := constraint.Parse(c.Text); err == nil {
+				return expr.String()
+			}
+		}
+	}
+	var expr constraint.Expr
+	for _, c := range lines {
+		x, err := constraint.Parse(c.Text)
+		if err != nil {
+			continue
+		}
+		if expr == nil {
+			expr = x
+		} else {
+			expr = &constraint.AndExpr{X: expr, Y: x}
+		}
+	}
+	if expr == nil {
+		return strings.TrimSpace(strings.TrimPrefix(lines[0].Text, "//"))
+	}
+	return expr.String()
+}
+
+// lastDirective returns the last directive of the doc comment of a declaration, nil if there isn't any. //line
+// directives only change the positions reported by the compiler, they don't govern the declaration.
+func lastDirective(doc *ast.CommentGroup) *ast.Comment {
+	if doc == nil {
+		return nil
+	}
+	for i := len(doc.List) - 1; i >= 0; i-- {
+		if isDirective(doc.List[i].Text) && directiveName(doc.List[i].Text) != "line" {
+			return doc.List[i]
+		}
+	}
+	return nil
+}
+
+// declDoc returns the doc comment of a top-level declaration.
+func declDoc(decl ast.Decl) *ast.CommentGroup {
+	switch d := decl.(type) {
+	case *ast.GenDecl:
+		return d.Doc
+	case *ast.FuncDecl:
+		return d.Doc
+	}
+	return nil
+}
+
+// commentNodes returns the nodes of the free-floating comment group cg: a Comment terminal for every run of ordinary
+// comments, a Directive terminal for every directive and, before the package clause, a BuildConstraint terminal for
+// the build constraint lines.
+func (v *visitor) commentNodes(cg *ast.CommentGroup, beforePackage bool) []*Terminal {
+	var nodes []*Terminal
+	var run []*ast.Comment
+	runType := Comment
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		group := &ast.CommentGroup{List: run}
+		tokenFile := v.FileSet.File(group.Pos())
+		end := commentEnd(v.FileSet, v.src, group)
+		var name string
+		switch runType {
+		case BuildConstraintNode:
+			name = buildConstraintName(run)
+		case DirectiveNode:
+			name = directiveName(run[0].Text)
+		default:
+			name = v.opts.commentName(group.Text())
+		}
+		nodes = append(nodes, &Terminal{
+			Type:         runType,
+			Name:         name,
+			LocationSpan: locationSpanFromPositions(v.FileSet, group.Pos(), tokenFile.Pos(end)),
+			Span:         RuneSpan{tokenFile.Offset(group.Pos()), end},
+		})
+		run = nil
+	}
+	for _, c := range cg.List {
+		nodeType := Comment
+		if beforePackage && isBuildConstraint(c.Text) {
+			nodeType = BuildConstraintNode
+		} else if isDirective(c.Text) {
+			nodeType = DirectiveNode
+		}
+		if nodeType != runType || nodeType == DirectiveNode {
+			flush()
+			runType = nodeType
+		}
+		run = append(run, c)
+	}
+	flush()
+	return nodes
+}
+
+// walkWithDirectives walks a top-level declaration whose doc comment has directives. Its nodes are wrapped in a
+// Directive container named after the declaration, with the doc comment up to the last directive as header, so the
+// directives move with the declaration and changing them doesn't change the declaration itself.
+func (v *visitor) walkWithDirectives(decl ast.Decl, last *ast.Comment) {
+	doc := declDoc(decl)
+	start := v.FileSet.PositionFor(doc.Pos(), false)
+	ffc := v.freeFloatingCommentsBefore(start.Offset)
+	v.AddFFCToParentContainer(ffc...)
+	container := &Container{
+		Type:         DirectiveNode,
+		LocationSpan: locationSpanFromPositions(v.FileSet, doc.Pos(), decl.End()),
+		HeaderSpan:   runeSpanFromPositions(v.FileSet, doc.Pos(), last.End()),
+	}
+	v.AddToParentContainer(container)
+	v.Push(decl, container)
+	ast.Walk(v, decl)
+	v.Pop()
+
+	// the footer is empty, right after decl and its line comment
+	end := v.FileSet.PositionFor(decl.End(), false).Offset
+	if len(container.Children) > 0 {
+		lastEnd := end
+		switch n := container.Children[len(container.Children)-1].(type) {
+		case *Terminal:
+			lastEnd = n.Span.End
+		case *Container:
+			lastEnd = n.FooterSpan.End
+		}
+		if lastEnd > end {
+			end = lastEnd
+		}
+	}
+	container.FooterSpan = RuneSpan{end + 1, end}
+	for _, child := range container.Children {
+		switch n := child.(type) {
+		case *Terminal:
+			if n.Type == Comment {
+				continue
+			}
+			container.Name = n.Name
+		case *Container:
+			container.Name = n.Name
+		}
+		return
+	}
+}
@@ -0,0 +1,174 @@
+package smgo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// lookupEncoding resolves name using the WHATWG (HTML) registry first and the IANA registry second.
+func lookupEncoding(name string) (encoding.Encoding, error) {
+	name = strings.TrimSpace(name)
+	if enc, err := htmlindex.Get(name); err == nil {
+		return enc, nil
+	}
+	// the IANA index knows names without an implementation, those are returned as a nil encoding
+	if enc, err := ianaindex.IANA.Encoding(name); err == nil && enc != nil {
+		return enc, nil
+	}
+	return nil, ErrUnsupportedEncoding
+}
+
+// EncodingName returns the canonical name of the encoding name (as resolved by Parse, not including auto), so aliases
+// like "latin1" and "ISO-8859-1" have the same name, or ErrUnsupportedEncoding.
+func EncodingName(name string) (string, error) {
+	enc, err := lookupEncoding(name)
+	if err != nil {
+		return "", err
+	}
+	if canonical, err := htmlindex.Name(enc); err == nil {
+		return canonical, nil
+	}
+	return ianaindex.IANA.Name(enc)
+}
+
+// bomEncoding returns the encoding given by the byte order mark at the start of src and its name, nil if there isn't
+// one. The UTF-16 decoders keep the BOM, so it's decoded as an UTF-8 BOM.
+func bomEncoding(src []byte) (encoding.Encoding, string) {
+	switch {
+	case bytes.HasPrefix(src, bomUTF8):
+		return unicode.UTF8, "UTF-8"
+	case bytes.HasPrefix(src, bomUTF16LE):
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM), "UTF-16LE"
+	case bytes.HasPrefix(src, bomUTF16BE):
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM), "UTF-16BE"
+	}
+	return nil, ""
+}
+
+// autoEncoding selects the encoding from the content: UTF-8 if it's valid UTF-8, the fallback encoding otherwise. The
+// fallback is Windows-1252 unless given as "auto:<encoding>".
+const autoEncoding = "auto"
+
+// autoFallback returns the fallback encoding of name, and whether name is the auto encoding.
+func autoFallback(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if strings.EqualFold(name, autoEncoding) {
+		return "windows-1252", true
+	}
+	if len(name) > len(autoEncoding) && strings.EqualFold(name[:len(autoEncoding)+1], autoEncoding+":") {
+		return name[len(autoEncoding)+1:], true
+	}
+	return "", false
+}
+
+// Decode reads src and returns its content as UTF-8, as seen by Parse. A byte order mark takes precedence over the
+// given encoding and is kept at the start of the result as an UTF-8 BOM: go/scanner skips it, and the spans still
+// account for it.
+func Decode(src io.Reader, name string) ([]byte, error) {
+	decoded, _, _, err := decode(src, name)
+	return decoded, err
+}
+
+// decode is like Decode, also returning the encoding chosen by the auto encoding (empty for other encodings) and the
+// errors found when it falls back to a legacy encoding: one for every byte not valid as UTF-8. Their locations are in
+// bytes of the decoded content.
+func decode(src io.Reader, name string) ([]byte, string, []*ParsingError, error) {
+	fallback, auto := autoFallback(name)
+	if auto {
+		name = fallback
+	}
+	enc, err := lookupEncoding(name)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	srcBytes, err := ioutil.ReadAll(src)
+	if err != nil {
+		return nil, "", nil, errors.Wrap(err, "Error reading src")
+	}
+	var autoName string
+	bomEnc, bomName := bomEncoding(srcBytes)
+	switch {
+	case bomEnc != nil:
+		enc = bomEnc
+		if auto {
+			autoName = bomName
+		}
+	case auto && utf8.Valid(srcBytes):
+		enc, autoName = unicode.UTF8, "UTF-8"
+	case auto:
+		decoded, errs, err := decodeInvalidUTF8(srcBytes, enc, fallback)
+		return decoded, fallback, errs, err
+	}
+	if enc == unicode.UTF8 {
+		return srcBytes, autoName, nil, nil
+	}
+	decoded, err := enc.NewDecoder().Bytes(srcBytes)
+	if err != nil {
+		return nil, "", nil, errors.Wrap(err, "Error decoding src")
+	}
+	return decoded, autoName, nil, nil
+}
+
+// decodeInvalidUTF8 decodes src with enc, the fallback of the auto encoding, returning an error for every byte of src
+// not valid as UTF-8. src is decoded in chunks delimited by those bytes, which is fine for the single-byte legacy
+// encodings.
+func decodeInvalidUTF8(src []byte, enc encoding.Encoding, name string) ([]byte, []*ParsingError, error) {
+	decoder := enc.NewDecoder()
+	decoded := make([]byte, 0, len(src))
+	var errs []*ParsingError
+	line, lineStart := 1, 0
+	decodeChunk := func(chunk []byte) error {
+		d, err := decoder.Bytes(chunk)
+		if err != nil {
+			return errors.Wrap(err, "Error decoding src")
+		}
+		if i := bytes.LastIndexByte(d, '\n'); i != -1 {
+			line += bytes.Count(d, []byte("\n"))
+			lineStart = len(decoded) + i + 1
+		}
+		decoded = append(decoded, d...)
+		return nil
+	}
+	chunkStart := 0
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRune(src[i:])
+		if r != utf8.RuneError || size != 1 {
+			i += size
+			continue
+		}
+		err := decodeChunk(src[chunkStart:i])
+		if err != nil {
+			return nil, nil, err
+		}
+		errs = append(errs, &ParsingError{
+			Location: Location{
+				Line:   line,
+				Column: len(decoded) - lineStart,
+			},
+			Message: fmt.Sprintf("invalid UTF-8 byte 0x%02X, decoded as %s", src[i], name),
+		})
+		chunkStart = i
+		i++
+	}
+	err := decodeChunk(src[chunkStart:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return decoded, errs, nil
+}
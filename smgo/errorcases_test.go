@@ -0,0 +1,208 @@
+package smgo_test
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/jriquelme/SemanticMergeGO/smgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrorCases(t *testing.T) {
+	t.Parallel()
+	parser := newTestParser()
+
+	cases := []struct {
+		Src          string
+		ExpectedFile *smgo.File
+	}{
+		{
+			Src: "error_decl.go_src",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 12, 11),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "errordecl",
+						LocationSpan: newLocationSpan(1, 0, 1, 18),
+						Span:         smgo.RuneSpan{0, 17},
+					},
+					&smgo.Terminal{
+						Type:         smgo.VarNode,
+						Name:         "X",
+						LocationSpan: newLocationSpan(2, 0, 3, 10),
+						Span:         smgo.RuneSpan{18, 28},
+					},
+					&smgo.Terminal{
+						Type:         smgo.UnparsedNode,
+						Name:         "unparsed",
+						LocationSpan: newLocationSpan(4, 0, 5, 4),
+						Span:         smgo.RuneSpan{29, 33},
+					},
+					&smgo.Container{
+						Type:         smgo.StructNode,
+						Name:         "Y",
+						LocationSpan: newLocationSpan(6, 0, 10, 2),
+						HeaderSpan:   smgo.RuneSpan{34, 63},
+						FooterSpan:   smgo.RuneSpan{77, 78},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.FieldNode,
+								Name:         "Name",
+								LocationSpan: newLocationSpan(9, 0, 9, 13),
+								Span:         smgo.RuneSpan{64, 76},
+							},
+						},
+					},
+					&smgo.Terminal{
+						Type:         smgo.UnparsedNode,
+						Name:         "unparsed#2",
+						LocationSpan: newLocationSpan(11, 0, 12, 11),
+						Span:         smgo.RuneSpan{79, 90},
+					},
+				},
+				ParsingErrors: []*smgo.ParsingError{
+					{
+						Location: smgo.Location{5, 0},
+						Message:  "expected declaration, found ')'",
+					},
+					{
+						Location: smgo.Location{12, 10},
+						Message:  "expected ')', found newline",
+					},
+					{
+						Location: smgo.Location{12, 11},
+						Message:  "expected ';', found 'EOF'",
+					},
+				},
+			},
+		},
+		{
+			Src: "error_func.go_src",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 10, 2),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "errorfunc",
+						LocationSpan: newLocationSpan(1, 0, 1, 18),
+						Span:         smgo.RuneSpan{0, 17},
+					},
+					&smgo.Terminal{
+						Type:         smgo.UnparsedNode,
+						Name:         "unparsed",
+						LocationSpan: newLocationSpan(2, 0, 6, 2),
+						Span:         smgo.RuneSpan{18, 68},
+					},
+					&smgo.Terminal{
+						Type:         smgo.FunctionNode,
+						Name:         "Fine",
+						LocationSpan: newLocationSpan(7, 0, 10, 2),
+						Span:         smgo.RuneSpan{69, 100},
+					},
+				},
+				ParsingErrors: []*smgo.ParsingError{
+					{
+						Location: smgo.Location{6, 0},
+						Message:  "expected operand, found '}'",
+					},
+				},
+			},
+		},
+		{
+			Src: "error_pkg.go_src",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 3, 12),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.UnparsedNode,
+						Name:         "unparsed",
+						LocationSpan: newLocationSpan(1, 0, 3, 12),
+						Span:         smgo.RuneSpan{0, 28},
+					},
+				},
+				ParsingErrors: []*smgo.ParsingError{
+					{
+						Location: smgo.Location{1, 0},
+						Message:  "expected 'package', found pakage",
+					},
+				},
+			},
+		},
+		{
+			Src: "error_pkg_comment.go_src",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 6, 12),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "p",
+						LocationSpan: newLocationSpan(1, 0, 1, 10),
+						Span:         smgo.RuneSpan{0, 9},
+					},
+					&smgo.Terminal{
+						Type:         smgo.Comment,
+						Name:         "the packag...",
+						LocationSpan: newLocationSpan(1, 10, 2, 4),
+						Span:         smgo.RuneSpan{10, 28},
+					},
+					&smgo.Terminal{
+						Type:         smgo.UnparsedNode,
+						Name:         "unparsed",
+						LocationSpan: newLocationSpan(3, 0, 4, 2),
+						Span:         smgo.RuneSpan{29, 40},
+					},
+					&smgo.Terminal{
+						Type:         smgo.FunctionNode,
+						Name:         "G",
+						LocationSpan: newLocationSpan(5, 0, 6, 12),
+						Span:         smgo.RuneSpan{41, 53},
+					},
+				},
+				ParsingErrors: []*smgo.ParsingError{
+					{
+						Location: smgo.Location{3, 8},
+						Message:  "expected ')', found '{'",
+					},
+					{
+						Location: smgo.Location{4, 0},
+						Message:  "missing ',' in parameter list",
+					},
+					{
+						Location: smgo.Location{4, 1},
+						Message:  "expected ')', found newline",
+					},
+					{
+						Location: smgo.Location{4, 1},
+						Message:  "missing ',' before newline in parameter list",
+					},
+				},
+			},
+		},
+	}
+	for _, testCase := range cases {
+		name := testCase.Src[len("error_"):strings.LastIndex(testCase.Src, ".")]
+		t.Run(name, func(t *testing.T) {
+			src, err := ioutil.ReadFile("testdata/" + testCase.Src)
+			require.Nil(t, err)
+
+			file, err := parser.ParseBytes(context.Background(), src, "UTF-8")
+			assert.NotNil(t, file)
+			assert.Nil(t, err)
+
+			assert.Equal(t, testCase.ExpectedFile, file)
+			assertTiling(t, file, src)
+			if t.Failed() {
+				spew.Dump(t.Name(), file)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package smgo_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jriquelme/SemanticMergeGO/smgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// FuzzParse checks that Parse doesn't fail, that the tree tiles the source and that parsing is deterministic, with the
+// default options and with import sections and coarse generated files. The crashers found so far are kept in
+// testdata/fuzz/FuzzParse.
+func FuzzParse(f *testing.F) {
+	srcs, err := filepath.Glob("testdata/*.go*")
+	require.Nil(f, err)
+	for _, src := range srcs {
+		content, err := ioutil.ReadFile(src)
+		require.Nil(f, err)
+		f.Add(content)
+	}
+	for _, src := range []string{
+		"package p",
+		"package p\n\nfunc F() {\n\t// inside\n}",
+		"package /* c */ p\n\ntype /* c */ T struct /* c */ {\n\tA /* c */ int\n}\n",
+		"package p\n\nconst ()\nvar ()\ntype ()\nimport ()\n",
+		"package p\n\nvar (\n\t// only a comment\n)\n",
+		"package p\n\nimport (\n\t\"fmt\"; \"os\"\n\n\t\"example.com/m/a\"\n\n\t\"github.com/pkg/errors\"\n)\n",
+		"// Code generated by x. DO NOT EDIT.\n\npackage p /* a\n */\n\nfunc F() {}\n",
+		"\xff\xfep\x00",
+	} {
+		f.Add([]byte(src))
+	}
+
+	parser := smgo.NewParser(smgo.WithValidation(true), smgo.WithImportSections(true), smgo.WithModulePath("example.com/m"),
+		smgo.WithGeneratedCode(smgo.GeneratedCoarse))
+	f.Fuzz(func(t *testing.T, src []byte) {
+		file, err := smgo.Parse(bytes.NewReader(src), "UTF-8")
+		require.Nil(t, err)
+		decoded, err := smgo.Decode(bytes.NewReader(src), "UTF-8")
+		require.Nil(t, err)
+		require.Empty(t, smgo.Validate(file, decoded))
+
+		again, err := smgo.Parse(bytes.NewReader(src), "UTF-8")
+		require.Nil(t, err)
+		assert.Equal(t, file, again)
+
+		_, err = parser.ParseBytes(context.Background(), src, "UTF-8")
+		require.Nil(t, err)
+	})
+}
@@ -0,0 +1,36 @@
+package smgo
+
+import (
+	"go/ast"
+	"regexp"
+	"strings"
+)
+
+// GeneratedMode sets how generated files (see File.Generated) are parsed.
+type GeneratedMode int
+
+const (
+	// GeneratedDetailed parses generated files like any other file.
+	GeneratedDetailed GeneratedMode = iota
+	// GeneratedCoarse emits everything after the package clause of a generated file as a single node, so merging
+	// regenerated code doesn't produce a conflict for every declaration.
+	GeneratedCoarse
+)
+
+// generatedComment matches the comment marking generated files, see https://golang.org/s/generatedcode.
+var generatedComment = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// isGenerated reports whether file has a generated code comment before the package clause, like go/ast.IsGenerated.
+func isGenerated(file *ast.File) bool {
+	for _, cg := range file.Comments {
+		if cg.Pos() > file.Package {
+			return false
+		}
+		for _, c := range cg.List {
+			if generatedComment.MatchString(strings.TrimSuffix(c.Text, "\r")) {
+				return true
+			}
+		}
+	}
+	return false
+}
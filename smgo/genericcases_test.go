@@ -0,0 +1,249 @@
+package smgo_test
+
+import (
+	"context"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/jriquelme/SemanticMergeGO/smgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGenericCases(t *testing.T) {
+	t.Parallel()
+	parser := newTestParser()
+
+	cases := []struct {
+		Src          string
+		ExpectedFile *smgo.File
+	}{
+		{
+			Src: "generic_constraints.go",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 26, 2),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "genericconstraints",
+						LocationSpan: newLocationSpan(1, 0, 1, 27),
+						Span:         smgo.RuneSpan{0, 26},
+					},
+					&smgo.Terminal{
+						Type:         smgo.ImportNode,
+						Name:         "fmt",
+						LocationSpan: newLocationSpan(2, 0, 3, 13),
+						Span:         smgo.RuneSpan{27, 40},
+					},
+					&smgo.Container{
+						Type:         smgo.InterfaceNode,
+						Name:         "Number",
+						LocationSpan: newLocationSpan(4, 0, 7, 2),
+						HeaderSpan:   smgo.RuneSpan{41, 65},
+						FooterSpan:   smgo.RuneSpan{92, 93},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.TypeSetNode,
+								Name:         "~int | ~int64 | ~float64",
+								LocationSpan: newLocationSpan(6, 0, 6, 26),
+								Span:         smgo.RuneSpan{66, 91},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.InterfaceNode,
+						Name:         "Stringish",
+						LocationSpan: newLocationSpan(8, 0, 13, 2),
+						HeaderSpan:   smgo.RuneSpan{94, 121},
+						FooterSpan:   smgo.RuneSpan{157, 158},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.TypeSetNode,
+								Name:         "~string",
+								LocationSpan: newLocationSpan(10, 0, 10, 9),
+								Span:         smgo.RuneSpan{122, 130},
+							},
+							&smgo.Terminal{
+								Type:         smgo.EmbeddedInterfaceNode,
+								Name:         "fmt.Stringer",
+								LocationSpan: newLocationSpan(11, 0, 11, 14),
+								Span:         smgo.RuneSpan{131, 144},
+							},
+							&smgo.Terminal{
+								Type:         smgo.EmbeddedInterfaceNode,
+								Name:         "comparable",
+								LocationSpan: newLocationSpan(12, 0, 12, 12),
+								Span:         smgo.RuneSpan{145, 156},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.InterfaceNode,
+						Name:         "Bytes",
+						LocationSpan: newLocationSpan(14, 0, 18, 2),
+						HeaderSpan:   smgo.RuneSpan{159, 182},
+						FooterSpan:   smgo.RuneSpan{211, 212},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.TypeSetNode,
+								Name:         "[]byte | string",
+								LocationSpan: newLocationSpan(16, 0, 16, 17),
+								Span:         smgo.RuneSpan{183, 199},
+							},
+							&smgo.Terminal{
+								Type:         smgo.MethodSpecNode,
+								Name:         "Len",
+								LocationSpan: newLocationSpan(17, 0, 17, 11),
+								Span:         smgo.RuneSpan{200, 210},
+							},
+						},
+					},
+					&smgo.Terminal{
+						Type:         smgo.FunctionNode,
+						Name:         "Sum",
+						LocationSpan: newLocationSpan(19, 0, 26, 2),
+						Span:         smgo.RuneSpan{213, 310},
+					},
+				},
+				ParsingErrors: nil,
+			},
+		},
+		{
+			Src: "generic_func.go",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 13, 2),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "genericfunc",
+						LocationSpan: newLocationSpan(1, 0, 1, 20),
+						Span:         smgo.RuneSpan{0, 19},
+					},
+					&smgo.Terminal{
+						Type:         smgo.FunctionNode,
+						Name:         "Map",
+						LocationSpan: newLocationSpan(2, 0, 9, 2),
+						Span:         smgo.RuneSpan{20, 152},
+					},
+					&smgo.Terminal{
+						Type:         smgo.FunctionNode,
+						Name:         "Keys",
+						LocationSpan: newLocationSpan(10, 0, 13, 2),
+						Span:         smgo.RuneSpan{153, 221},
+					},
+				},
+				ParsingErrors: nil,
+			},
+		},
+		{
+			Src: "generic_types.go",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 22, 2),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "generictypes",
+						LocationSpan: newLocationSpan(1, 0, 1, 21),
+						Span:         smgo.RuneSpan{0, 20},
+					},
+					&smgo.Container{
+						Type:         smgo.StructNode,
+						Name:         "Set",
+						LocationSpan: newLocationSpan(2, 0, 5, 2),
+						HeaderSpan:   smgo.RuneSpan{21, 53},
+						FooterSpan:   smgo.RuneSpan{76, 77},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.FieldNode,
+								Name:         "items",
+								LocationSpan: newLocationSpan(4, 0, 4, 22),
+								Span:         smgo.RuneSpan{54, 75},
+							},
+						},
+					},
+					&smgo.Terminal{
+						Type:         smgo.TypeNode,
+						Name:         "List",
+						LocationSpan: newLocationSpan(6, 0, 7, 21),
+						Span:         smgo.RuneSpan{78, 99},
+					},
+					&smgo.Container{
+						Type:         smgo.TypeNode,
+						Name:         "type",
+						LocationSpan: newLocationSpan(8, 0, 18, 2),
+						HeaderSpan:   smgo.RuneSpan{100, 107},
+						FooterSpan:   smgo.RuneSpan{238, 239},
+						Children: []smgo.Node{
+							&smgo.Container{
+								Type:         smgo.StructNode,
+								Name:         "Pair",
+								LocationSpan: newLocationSpan(10, 0, 13, 3),
+								HeaderSpan:   smgo.RuneSpan{108, 143},
+								FooterSpan:   smgo.RuneSpan{164, 166},
+								Children: []smgo.Node{
+									&smgo.Terminal{
+										Type:         smgo.FieldNode,
+										Name:         "Key",
+										LocationSpan: newLocationSpan(11, 0, 11, 10),
+										Span:         smgo.RuneSpan{144, 153},
+									},
+									&smgo.Terminal{
+										Type:         smgo.FieldNode,
+										Name:         "Value",
+										LocationSpan: newLocationSpan(12, 0, 12, 10),
+										Span:         smgo.RuneSpan{154, 163},
+									},
+								},
+							},
+							&smgo.Container{
+								Type:         smgo.StructNode,
+								Name:         "Tree",
+								LocationSpan: newLocationSpan(14, 0, 17, 3),
+								HeaderSpan:   smgo.RuneSpan{167, 211},
+								FooterSpan:   smgo.RuneSpan{235, 237},
+								Children: []smgo.Node{
+									&smgo.Terminal{
+										Type:         smgo.FieldNode,
+										Name:         "Left, Right",
+										LocationSpan: newLocationSpan(16, 0, 16, 23),
+										Span:         smgo.RuneSpan{212, 234},
+										Names:        []string{"Left", "Right"},
+									},
+								},
+							},
+						},
+					},
+					&smgo.Terminal{
+						Type:         smgo.MethodNode,
+						Name:         "(*Set).Add",
+						LocationSpan: newLocationSpan(19, 0, 22, 2),
+						Span:         smgo.RuneSpan{240, 295},
+					},
+				},
+				ParsingErrors: nil,
+			},
+		},
+	}
+	for _, testCase := range cases {
+		name := testCase.Src[len("generic_"):strings.LastIndex(testCase.Src, ".")]
+		t.Run(name, func(t *testing.T) {
+			src, err := ioutil.ReadFile("testdata/" + testCase.Src)
+			require.Nil(t, err)
+
+			file, err := parser.ParseBytes(context.Background(), src, "UTF-8")
+			assert.NotNil(t, file)
+			assert.Nil(t, err)
+
+			assert.Equal(t, testCase.ExpectedFile, file)
+			assertTiling(t, file, src)
+			if t.Failed() {
+				spew.Dump(t.Name(), file)
+			}
+		})
+	}
+}
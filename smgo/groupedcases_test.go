@@ -1,6 +1,7 @@
 package smgo_test
 
 import (
+	"context"
 	"os"
 	"strings"
 	"testing"
@@ -13,9 +14,7 @@ import (
 
 func TestParseGroupedCases(t *testing.T) {
 	t.Parallel()
-	if testing.Verbose() {
-		smgo.PrintBlocks = true
-	}
+	parser := newTestParser()
 
 	cases := []struct {
 		Src          string
@@ -56,7 +55,7 @@ func TestParseGroupedCases(t *testing.T) {
 					},
 					&smgo.Container{
 						Type:         smgo.ConstNode,
-						Name:         "const",
+						Name:         "const#2",
 						LocationSpan: newLocationSpan(7, 0, 8, 9),
 						HeaderSpan:   smgo.RuneSpan{69, 76},
 						FooterSpan:   smgo.RuneSpan{77, 78},
@@ -107,7 +106,7 @@ func TestParseGroupedCases(t *testing.T) {
 					},
 					&smgo.Container{
 						Type:         smgo.ImportNode,
-						Name:         "import",
+						Name:         "import#2",
 						LocationSpan: newLocationSpan(9, 0, 10, 10),
 						HeaderSpan:   smgo.RuneSpan{79, 87},
 						FooterSpan:   smgo.RuneSpan{88, 89},
@@ -149,7 +148,7 @@ func TestParseGroupedCases(t *testing.T) {
 								Span:         smgo.RuneSpan{41, 55},
 							},
 							&smgo.Terminal{
-								Type:         smgo.TypeNode,
+								Type:         smgo.AliasNode,
 								Name:         "StringAlias",
 								LocationSpan: newLocationSpan(7, 0, 8, 22),
 								Span:         smgo.RuneSpan{56, 78},
@@ -225,13 +224,13 @@ func TestParseGroupedCases(t *testing.T) {
 								FooterSpan:   smgo.RuneSpan{331, 333},
 								Children: []smgo.Node{
 									&smgo.Terminal{
-										Type:         smgo.FieldNode,
+										Type:         smgo.MethodSpecNode,
 										Name:         "Area",
 										LocationSpan: newLocationSpan(29, 0, 29, 17),
 										Span:         smgo.RuneSpan{292, 308},
 									},
 									&smgo.Terminal{
-										Type:         smgo.FieldNode,
+										Type:         smgo.MethodSpecNode,
 										Name:         "Perimeter",
 										LocationSpan: newLocationSpan(30, 0, 30, 22),
 										Span:         smgo.RuneSpan{309, 330},
@@ -242,7 +241,7 @@ func TestParseGroupedCases(t *testing.T) {
 					},
 					&smgo.Container{
 						Type:         smgo.TypeNode,
-						Name:         "type",
+						Name:         "type#2",
 						LocationSpan: newLocationSpan(33, 0, 34, 8),
 						HeaderSpan:   smgo.RuneSpan{336, 342},
 						FooterSpan:   smgo.RuneSpan{343, 344},
@@ -287,7 +286,7 @@ func TestParseGroupedCases(t *testing.T) {
 					},
 					&smgo.Container{
 						Type:         smgo.VarNode,
-						Name:         "var",
+						Name:         "var#2",
 						LocationSpan: newLocationSpan(8, 0, 9, 7),
 						HeaderSpan:   smgo.RuneSpan{54, 59},
 						FooterSpan:   smgo.RuneSpan{60, 61},
@@ -297,6 +296,65 @@ func TestParseGroupedCases(t *testing.T) {
 				ParsingErrors: nil,
 			},
 		},
+		{
+			Src: "grouped_import_names.go_src",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 11, 29),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "groupedimportnames",
+						LocationSpan: newLocationSpan(1, 0, 1, 27),
+						Span:         smgo.RuneSpan{0, 26},
+					},
+					&smgo.Container{
+						Type:         smgo.ImportNode,
+						Name:         "import",
+						LocationSpan: newLocationSpan(2, 0, 9, 2),
+						HeaderSpan:   smgo.RuneSpan{27, 36},
+						FooterSpan:   smgo.RuneSpan{114, 115},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.ImportNode,
+								Name:         "fmt",
+								LocationSpan: newLocationSpan(4, 0, 4, 7),
+								Span:         smgo.RuneSpan{37, 43},
+							},
+							&smgo.Terminal{
+								Type:         smgo.ImportNode,
+								Name:         ". testing",
+								LocationSpan: newLocationSpan(5, 0, 5, 13),
+								Span:         smgo.RuneSpan{44, 56},
+								LocalName:    ".",
+							},
+							&smgo.Terminal{
+								Type:         smgo.ImportNode,
+								Name:         "_ net/http/pprof",
+								LocationSpan: newLocationSpan(6, 0, 6, 20),
+								Span:         smgo.RuneSpan{57, 76},
+								LocalName:    "_",
+							},
+							&smgo.Terminal{
+								Type:         smgo.ImportNode,
+								Name:         "yaml gopkg.in/yaml.v2",
+								LocationSpan: newLocationSpan(7, 0, 8, 36),
+								Span:         smgo.RuneSpan{77, 113},
+								LocalName:    "yaml",
+							},
+						},
+					},
+					&smgo.Terminal{
+						Type:         smgo.ImportNode,
+						Name:         "y2 gopkg.in/yaml.v2",
+						LocationSpan: newLocationSpan(10, 0, 11, 29),
+						Span:         smgo.RuneSpan{116, 145},
+						LocalName:    "y2",
+					},
+				},
+				ParsingErrors: nil,
+			},
+		},
 	}
 	for _, testCase := range cases {
 		name := testCase.Src[len("grouped_"):strings.LastIndex(testCase.Src, ".")]
@@ -305,7 +363,7 @@ func TestParseGroupedCases(t *testing.T) {
 			require.Nil(t, err)
 			defer srcFile.Close()
 
-			file, err := smgo.Parse(srcFile, "UTF-8")
+			file, err := parser.ParseReader(context.Background(), srcFile, "UTF-8")
 			assert.NotNil(t, file)
 			assert.Nil(t, err)
 
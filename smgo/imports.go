@@ -0,0 +1,181 @@
+package smgo
+
+import (
+	"bufio"
+	"bytes"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Import section names, after the kind of the paths imported.
+const (
+	stdSection         = "std"
+	moduleLocalSection = "module-local"
+	thirdPartySection  = "third-party"
+	mixedSection       = "mixed"
+)
+
+// importSection returns the kind of the import path: module-local if it's modulePath or a package in it, std if its
+// first element has no dot (like goimports does), third-party otherwise.
+func importSection(path, modulePath string) string {
+	switch {
+	case modulePath != "" && (path == modulePath || strings.HasPrefix(path, modulePath+"/")):
+		return moduleLocalSection
+	case !strings.Contains(strings.SplitN(path, "/", 2)[0], "."):
+		return stdSection
+	}
+	return thirdPartySection
+}
+
+// splitImportSections moves the children of an import group into ImportSection containers, one for every run of
+// imports separated by blank lines, named after the kind of the paths imported, or mixed. Comments stay in the run
+// they follow. The header of a section is the blank lines and indentation before it, so the group is left flat if
+// the first section isn't indented.
+func (v *visitor) splitImportSections(group *Container) {
+	if len(group.Children) == 0 {
+		return
+	}
+	// covered is the offset of the last char of the block before the next section, once the boundaries are fixed
+	covered := group.HeaderSpan.End
+	if end := lineTerminatorEnd(v.src, covered+1); end != -1 {
+		covered = end
+	}
+	var sections []Node
+	var section *Container
+	var kinds []string
+	closeSection := func(end int) {
+		section.Name = mixedSection
+		if len(kinds) > 0 {
+			section.Name = kinds[0]
+		}
+		for _, kind := range kinds {
+			if kind != section.Name {
+				section.Name = mixedSection
+				break
+			}
+		}
+		section.FooterSpan = RuneSpan{end + 1, end}
+		section.LocationSpan.End = locationFromPosition(v.FileSet, token.Pos(end+1))
+	}
+	prevEnd := covered
+	for _, child := range group.Children {
+		start, end, path := importChild(child)
+		if section == nil || path != "" && hasBlankLine(v.src[prevEnd:start]) {
+			headerEnd := firstNonSpace(v.src, covered+1) - 1
+			if headerEnd <= covered {
+				return
+			}
+			if section != nil {
+				closeSection(prevEnd)
+			}
+			section = &Container{
+				Type:         ImportSectionNode,
+				LocationSpan: LocationSpan{Start: locationFromPosition(v.FileSet, token.Pos(covered+2))},
+				HeaderSpan:   RuneSpan{covered + 1, headerEnd},
+			}
+			sections = append(sections, section)
+			kinds = nil
+		}
+		section.AddNode(child)
+		if path != "" {
+			kinds = append(kinds, importSection(path, v.opts.modulePath))
+		}
+		prevEnd = end
+		covered = end
+		if end := lineTerminatorEnd(v.src, end); end != -1 {
+			covered = end
+		}
+	}
+	closeSection(prevEnd)
+	group.Children = sections
+}
+
+// importChild returns the offsets of the first char and of the char after a child of an import group, and the path
+// imported, empty for comments.
+func importChild(child Node) (int, int, string) {
+	switch n := child.(type) {
+	case *Terminal:
+		if n.Type != ImportNode {
+			return n.Span.Start, n.Span.End, ""
+		}
+		path := n.Name
+		if n.LocalName != "" {
+			path = strings.TrimPrefix(path, n.LocalName+" ")
+		}
+		return n.Span.Start, n.Span.End, path
+	case *Container:
+		// import "C" with a preamble
+		return n.HeaderSpan.Start, n.FooterSpan.End, n.Name
+	}
+	return 0, 0, ""
+}
+
+// hasBlankLine reports whether text, from the end of a line to the start of another, has a blank line in between.
+func hasBlankLine(text []byte) bool {
+	lines := bytes.Split(text, []byte("\n"))
+	if len(lines) < 3 {
+		return false
+	}
+	for _, line := range lines[1 : len(lines)-1] {
+		if len(bytes.TrimSpace(line)) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// firstNonSpace returns the offset of the first char of src, from offset on, that isn't white space.
+func firstNonSpace(src []byte, offset int) int {
+	for offset < len(src) && strings.IndexByte(" \t\r\n", src[offset]) != -1 {
+		offset++
+	}
+	return offset
+}
+
+// ModulePath returns the module path declared by the go.mod file in dir or the nearest of its parents, empty if
+// there isn't any.
+func ModulePath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		f, err := os.Open(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			defer f.Close()
+			return modulePath(f)
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// modulePath returns the path of the module directive of a go.mod file.
+func modulePath(r io.Reader) (string, error) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := s.Text()
+		if i := strings.Index(line, "//"); i != -1 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+		if path, err := strconv.Unquote(fields[1]); err == nil {
+			return path, nil
+		}
+		return fields[1], nil
+	}
+	return "", s.Err()
+}
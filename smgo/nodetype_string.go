@@ -4,9 +4,9 @@ package smgo
 
 import "strconv"
 
-const _NodeType_name = "PackageNodeFunctionNodeFieldNodeImportNodeConstNodeVarNodeTypeNodeStructNodeInterfaceNodeComment"
+const _NodeType_name = "PackageNodeFunctionNodeFieldNodeImportNodeConstNodeVarNodeTypeNodeStructNodeInterfaceNodeCommentMethodNodeEmbeddedFieldNodeEmbeddedInterfaceNodeMethodSpecNodeTypeSetNodeAliasNodeUnparsedNodeBuildConstraintNodeDirectiveNodeCgoPreambleNodeCgoDeclNodeGeneratedNodeImportSectionNode"
 
-var _NodeType_index = [...]uint8{0, 11, 23, 32, 42, 51, 58, 66, 76, 89, 96}
+var _NodeType_index = [...]uint16{0, 11, 23, 32, 42, 51, 58, 66, 76, 89, 96, 106, 123, 144, 158, 169, 178, 190, 209, 222, 237, 248, 261, 278}
 
 func (i NodeType) String() string {
 	if i < 0 || i >= NodeType(len(_NodeType_index)-1) {
@@ -0,0 +1,122 @@
+package smgo
+
+import (
+	"io"
+	"strings"
+)
+
+// options are the settings of a Parser, copied into every call so they can't change while parsing.
+type options struct {
+	debug          io.Writer
+	commentName    func(text string) string
+	unit           SpanUnit
+	recovery       bool
+	validation     bool
+	typeMembers    bool
+	anonymousTypes bool
+	generated      GeneratedMode
+	importSections bool
+	modulePath     string
+}
+
+func defaultOptions() options {
+	return options{
+		commentName:    truncatedCommentName,
+		unit:           Chars,
+		recovery:       true,
+		typeMembers:    true,
+		anonymousTypes: true,
+	}
+}
+
+// Option configures a Parser.
+type Option func(*options)
+
+// WithDebugWriter makes the parser print the blocks of every tree, before and after fixing their boundaries, to w.
+// The output of a call is written at once, but w must be safe for concurrent use if the parser is.
+func WithDebugWriter(w io.Writer) Option {
+	return func(o *options) {
+		o.debug = w
+	}
+}
+
+// WithCommentNamer sets the function naming free-floating Comment nodes, given the text of the comment (as returned
+// by ast.CommentGroup.Text). By default, names are the first 10 bytes of the trimmed text.
+func WithCommentNamer(name func(text string) string) Option {
+	return func(o *options) {
+		o.commentName = name
+	}
+}
+
+// WithSpanUnit sets the unit of spans and columns, Chars by default.
+func WithSpanUnit(unit SpanUnit) Option {
+	return func(o *options) {
+		o.unit = unit
+	}
+}
+
+// WithErrorRecovery sets whether the parser recovers from syntax errors (the default), building the tree of the
+// declarations that parse cleanly and leaving the rest as unparsed nodes. Without recovery, a file with syntax errors
+// is a single unparsed node.
+func WithErrorRecovery(enabled bool) Option {
+	return func(o *options) {
+		o.recovery = enabled
+	}
+}
+
+// WithValidation makes the parser validate the trees it builds (see Validate), returning a *ValidationError if they
+// are invalid.
+func WithValidation(enabled bool) Option {
+	return func(o *options) {
+		o.validation = enabled
+	}
+}
+
+// WithTypeMembers sets whether struct fields and interface methods are nodes of their own (the default). Otherwise,
+// struct and interface types are terminals.
+func WithTypeMembers(enabled bool) Option {
+	return func(o *options) {
+		o.typeMembers = enabled
+	}
+}
+
+// WithAnonymousTypes sets whether the fields of anonymous struct and interface types, used by variables and struct
+// fields, are nodes of their own (the default). Otherwise, those variables and fields are terminals.
+func WithAnonymousTypes(enabled bool) Option {
+	return func(o *options) {
+		o.anonymousTypes = enabled
+	}
+}
+
+// WithGeneratedCode sets how generated files are parsed, GeneratedDetailed by default.
+func WithGeneratedCode(mode GeneratedMode) Option {
+	return func(o *options) {
+		o.generated = mode
+	}
+}
+
+// WithImportSections makes the parser split the imports of every group into sections, one for every run of imports
+// separated by blank lines, like goimports does. Sections are ImportSection containers named after the imports in
+// them: std, module-local or third-party, mixed if they aren't alike.
+func WithImportSections(enabled bool) Option {
+	return func(o *options) {
+		o.importSections = enabled
+	}
+}
+
+// WithModulePath sets the path of the module of the parsed files, telling module-local imports apart in import
+// sections. Otherwise, ParseFile looks it up in the nearest go.mod (see ModulePath).
+func WithModulePath(path string) Option {
+	return func(o *options) {
+		o.modulePath = path
+	}
+}
+
+// truncatedCommentName is the default name of a free-floating comment.
+func truncatedCommentName(text string) string {
+	name := strings.TrimSpace(text)
+	if len(name) > 10 {
+		name = name[0:10] + "..."
+	}
+	return name
+}
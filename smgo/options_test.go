@@ -0,0 +1,372 @@
+package smgo_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jriquelme/SemanticMergeGO/smgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestParser returns a parser printing the blocks of every tree in verbose mode.
+func newTestParser() *smgo.Parser {
+	if testing.Verbose() {
+		return smgo.NewParser(smgo.WithDebugWriter(os.Stdout))
+	}
+	return smgo.NewParser()
+}
+
+func TestParserDebugWriter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	parser := smgo.NewParser(smgo.WithDebugWriter(&buf))
+	_, err := parser.ParseBytes(context.Background(), []byte("package p\n\nvar A = 1\n"), "UTF-8")
+	require.Nil(t, err)
+	assert.Contains(t, buf.String(), "----------original blocks----------")
+	assert.Contains(t, buf.String(), "----------fixed blocks----------")
+}
+
+func TestParserCommentNamer(t *testing.T) {
+	t.Parallel()
+
+	parser := smgo.NewParser(smgo.WithCommentNamer(func(text string) string {
+		return "comment: " + strings.TrimSpace(text)
+	}))
+	file, err := parser.ParseBytes(context.Background(), []byte("package p\n\n// a free-floating comment\n\nvar A = 1\n"), "UTF-8")
+	require.Nil(t, err)
+	require.Len(t, file.Children, 3)
+	assert.Equal(t, "comment: a free-floating comment", file.Children[1].(*smgo.Terminal).Name)
+}
+
+func TestParserGranularity(t *testing.T) {
+	t.Parallel()
+
+	src := []byte("package p\n\ntype T struct {\n\tA int\n\tB struct {\n\t\tC int\n\t}\n}\n\ntype I interface {\n\tM()\n}\n")
+
+	parser := smgo.NewParser(smgo.WithTypeMembers(false))
+	file, err := parser.ParseBytes(context.Background(), src, "UTF-8")
+	require.Nil(t, err)
+	assertTiling(t, file, src)
+	expected := []smgo.Node{
+		&smgo.Terminal{
+			Type:         smgo.PackageNode,
+			Name:         "p",
+			LocationSpan: newLocationSpan(1, 0, 1, 10),
+			Span:         smgo.RuneSpan{0, 9},
+		},
+		&smgo.Terminal{
+			Type:         smgo.StructNode,
+			Name:         "T",
+			LocationSpan: newLocationSpan(2, 0, 8, 2),
+			Span:         smgo.RuneSpan{10, 58},
+		},
+		&smgo.Terminal{
+			Type:         smgo.InterfaceNode,
+			Name:         "I",
+			LocationSpan: newLocationSpan(9, 0, 12, 2),
+			Span:         smgo.RuneSpan{59, 85},
+		},
+	}
+	assert.Equal(t, expected, file.Children)
+
+	src = append(src, "\nvar V struct {\n\tD int\n}\n"...)
+	parser = smgo.NewParser(smgo.WithAnonymousTypes(false))
+	file, err = parser.ParseBytes(context.Background(), src, "UTF-8")
+	require.Nil(t, err)
+	assertTiling(t, file, src)
+	require.Len(t, file.Children, 4)
+	assert.Equal(t, &smgo.Terminal{
+		Type:         smgo.VarNode,
+		Name:         "V",
+		LocationSpan: newLocationSpan(13, 0, 16, 2),
+		Span:         smgo.RuneSpan{86, 110},
+	}, file.Children[3])
+	structNode := file.Children[1].(*smgo.Container)
+	require.Len(t, structNode.Children, 2)
+	assert.Equal(t, &smgo.Terminal{
+		Type:         smgo.FieldNode,
+		Name:         "B",
+		LocationSpan: newLocationSpan(5, 0, 7, 3),
+		Span:         smgo.RuneSpan{34, 56},
+	}, structNode.Children[1])
+}
+
+func TestParserErrorRecovery(t *testing.T) {
+	t.Parallel()
+
+	src, err := ioutil.ReadFile("testdata/error_func.go_src")
+	require.Nil(t, err)
+
+	parser := smgo.NewParser(smgo.WithErrorRecovery(false))
+	file, err := parser.ParseBytes(context.Background(), src, "UTF-8")
+	require.Nil(t, err)
+	assert.NotEmpty(t, file.ParsingErrors)
+	require.Len(t, file.Children, 1)
+	assert.Equal(t, smgo.UnparsedNode, file.Children[0].(*smgo.Terminal).Type)
+	assertTiling(t, file, src)
+
+	// without errors, recovery doesn't matter
+	src, err = ioutil.ReadFile("testdata/simple_func.go")
+	require.Nil(t, err)
+	expected, err := smgo.Parse(bytes.NewReader(src), "UTF-8")
+	require.Nil(t, err)
+	file, err = parser.ParseBytes(context.Background(), src, "UTF-8")
+	require.Nil(t, err)
+	assert.Equal(t, expected, file)
+}
+
+func TestParserValidation(t *testing.T) {
+	t.Parallel()
+
+	parser := smgo.NewParser(smgo.WithValidation(true), smgo.WithSpanUnit(smgo.Bytes))
+	file, err := parser.ParseBytes(context.Background(), []byte("package p\n\nconst Ñ = \"ñ\"\n"), "UTF-8")
+	require.Nil(t, err)
+	assert.Equal(t, smgo.RuneSpan{10, 26}, file.Children[1].(*smgo.Terminal).Span)
+}
+
+func TestParserContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	file, err := smgo.NewParser().ParseBytes(ctx, []byte("package p\n\nvar A = 1\n"), "UTF-8")
+	assert.Nil(t, file)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestParserParseFile(t *testing.T) {
+	t.Parallel()
+
+	expected, err := smgo.NewParser().ParseFile(context.Background(), "testdata/simple_struct.go", "UTF-8")
+	require.Nil(t, err)
+	srcFile, err := os.Open("testdata/simple_struct.go")
+	require.Nil(t, err)
+	defer srcFile.Close()
+	file, err := smgo.Parse(srcFile, "UTF-8")
+	require.Nil(t, err)
+	assert.Equal(t, expected, file)
+
+	_, err = smgo.NewParser().ParseFile(context.Background(), "testdata/missing.go", "UTF-8")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestParserConcurrent(t *testing.T) {
+	t.Parallel()
+
+	srcs, err := filepath.Glob("testdata/*.go")
+	require.Nil(t, err)
+	expected := make([]*smgo.File, len(srcs))
+	for i, src := range srcs {
+		expected[i], err = smgo.Parse(strings.NewReader(mustReadFile(t, src)), "UTF-8")
+		require.Nil(t, err)
+	}
+
+	parser := smgo.NewParser()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, src := range srcs {
+				file, err := parser.ParseFile(context.Background(), src, "UTF-8")
+				if assert.Nil(t, err, src) {
+					assert.Equal(t, expected[i], file, src)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func mustReadFile(t *testing.T, name string) string {
+	src, err := ioutil.ReadFile(name)
+	require.Nil(t, err)
+	return string(src)
+}
+
+func TestParserGeneratedCode(t *testing.T) {
+	t.Parallel()
+
+	src := []byte("// Code generated by stringer; DO NOT EDIT.\n\npackage p\n\nimport \"strconv\"\n\nfunc (i T) String() string {\n\treturn strconv.Itoa(int(i))\n}\n")
+	file, err := smgo.NewParser().ParseBytes(context.Background(), src, "UTF-8")
+	require.Nil(t, err)
+	assert.True(t, file.Generated)
+	assert.Len(t, file.Children, 4)
+
+	parser := smgo.NewParser(smgo.WithGeneratedCode(smgo.GeneratedCoarse))
+	file, err = parser.ParseBytes(context.Background(), src, "UTF-8")
+	require.Nil(t, err)
+	assert.True(t, file.Generated)
+	assertTiling(t, file, src)
+	expected := []smgo.Node{
+		&smgo.Terminal{
+			Type:         smgo.Comment,
+			Name:         "Code gener...",
+			LocationSpan: newLocationSpan(1, 0, 1, 44),
+			Span:         smgo.RuneSpan{0, 43},
+		},
+		&smgo.Terminal{
+			Type:         smgo.PackageNode,
+			Name:         "p",
+			LocationSpan: newLocationSpan(2, 0, 3, 10),
+			Span:         smgo.RuneSpan{44, 54},
+		},
+		&smgo.Terminal{
+			Type:         smgo.GeneratedNode,
+			Name:         "generated",
+			LocationSpan: newLocationSpan(4, 0, 9, 2),
+			Span:         smgo.RuneSpan{55, 133},
+		},
+	}
+	assert.Equal(t, expected, file.Children)
+
+	// the node starts after the comments on the package line
+	src = []byte("// Code generated by x. DO NOT EDIT.\n\npackage p /* a\n */\n\nfunc F() {}\n")
+	file, err = parser.ParseBytes(context.Background(), src, "UTF-8")
+	require.Nil(t, err)
+	assertTiling(t, file, src)
+	require.Len(t, file.Children, 4)
+	assert.Equal(t, smgo.Comment, file.Children[2].(*smgo.Terminal).Type)
+	assert.Equal(t, smgo.GeneratedNode, file.Children[3].(*smgo.Terminal).Type)
+
+	// only files marked as generated are coarse
+	src = []byte("package p\n\n// Code generated by hand; DO NOT EDIT.\nvar V = 1\n")
+	file, err = parser.ParseBytes(context.Background(), src, "UTF-8")
+	require.Nil(t, err)
+	assert.False(t, file.Generated)
+	assert.Equal(t, smgo.VarNode, file.Children[1].(*smgo.Terminal).Type)
+}
+
+func TestParserImportSections(t *testing.T) {
+	t.Parallel()
+
+	src := []byte("package p\n\nimport (\n\t\"fmt\"\n\t\"os\"\n\n\t// the module\n\tm \"example.com/m/a\"\n\n\t\"github.com/pkg/errors\"\n\t\"example.com/m\"\n)\n")
+	parser := smgo.NewParser(smgo.WithImportSections(true), smgo.WithModulePath("example.com/m"))
+	file, err := parser.ParseBytes(context.Background(), src, "UTF-8")
+	require.Nil(t, err)
+	assertTiling(t, file, src)
+	require.Len(t, file.Children, 2)
+	expected := []smgo.Node{
+		&smgo.Container{
+			Type:         smgo.ImportSectionNode,
+			Name:         "std",
+			LocationSpan: newLocationSpan(4, 0, 5, 6),
+			HeaderSpan:   smgo.RuneSpan{20, 20},
+			FooterSpan:   smgo.RuneSpan{33, 32},
+			Children: []smgo.Node{
+				&smgo.Terminal{
+					Type:         smgo.ImportNode,
+					Name:         "fmt",
+					LocationSpan: newLocationSpan(4, 1, 4, 7),
+					Span:         smgo.RuneSpan{21, 26},
+				},
+				&smgo.Terminal{
+					Type:         smgo.ImportNode,
+					Name:         "os",
+					LocationSpan: newLocationSpan(5, 0, 5, 6),
+					Span:         smgo.RuneSpan{27, 32},
+				},
+			},
+		},
+		&smgo.Container{
+			Type:         smgo.ImportSectionNode,
+			Name:         "module-local",
+			LocationSpan: newLocationSpan(6, 0, 8, 21),
+			HeaderSpan:   smgo.RuneSpan{33, 34},
+			FooterSpan:   smgo.RuneSpan{70, 69},
+			Children: []smgo.Node{
+				&smgo.Terminal{
+					Type:         smgo.ImportNode,
+					Name:         "m example.com/m/a",
+					LocationSpan: newLocationSpan(7, 1, 8, 21),
+					Span:         smgo.RuneSpan{35, 69},
+					LocalName:    "m",
+				},
+			},
+		},
+		&smgo.Container{
+			Type:         smgo.ImportSectionNode,
+			Name:         "mixed",
+			LocationSpan: newLocationSpan(9, 0, 11, 17),
+			HeaderSpan:   smgo.RuneSpan{70, 71},
+			FooterSpan:   smgo.RuneSpan{113, 112},
+			Children: []smgo.Node{
+				&smgo.Terminal{
+					Type:         smgo.ImportNode,
+					Name:         "github.com/pkg/errors",
+					LocationSpan: newLocationSpan(10, 1, 10, 25),
+					Span:         smgo.RuneSpan{72, 95},
+				},
+				&smgo.Terminal{
+					Type:         smgo.ImportNode,
+					Name:         "example.com/m",
+					LocationSpan: newLocationSpan(11, 0, 11, 17),
+					Span:         smgo.RuneSpan{96, 112},
+				},
+			},
+		},
+	}
+	assert.Equal(t, expected, file.Children[1].(*smgo.Container).Children)
+
+	// without the module path, only std imports are told apart
+	file, err = smgo.NewParser(smgo.WithImportSections(true)).ParseBytes(context.Background(), src, "UTF-8")
+	require.Nil(t, err)
+	var names []string
+	for _, section := range file.Children[1].(*smgo.Container).Children {
+		names = append(names, section.(*smgo.Container).Name)
+	}
+	assert.Equal(t, []string{"std", "third-party", "third-party#2"}, names)
+
+	// specs on the same line
+	for _, src := range []string{
+		"package p\n\nimport (\n\t\"fmt\"; \"os\"\n\n\t\"github.com/pkg/errors\"\n)\n",
+		"package p\n\nimport ( \"fmt\"; \"os\" )\n",
+	} {
+		file, err = smgo.NewParser(smgo.WithImportSections(true), smgo.WithValidation(true)).ParseBytes(context.Background(), []byte(src), "UTF-8")
+		require.Nil(t, err, src)
+		assertTiling(t, file, []byte(src))
+		section := file.Children[1].(*smgo.Container).Children[0].(*smgo.Container)
+		assert.Equal(t, "std", section.Name)
+		assert.Len(t, section.Children, 2)
+	}
+
+	// sections need indented imports
+	src = []byte("package p\n\nimport (\n\"fmt\"\n\n\"os\"\n)\n")
+	file, err = smgo.NewParser(smgo.WithImportSections(true)).ParseBytes(context.Background(), src, "UTF-8")
+	require.Nil(t, err)
+	assertTiling(t, file, src)
+	assert.Len(t, file.Children[1].(*smgo.Container).Children, 2)
+	assert.Equal(t, smgo.ImportNode, file.Children[1].(*smgo.Container).Children[0].(*smgo.Terminal).Type)
+}
+
+func TestModulePath(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "smgo")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	pkg := filepath.Join(dir, "internal", "pkg")
+	require.Nil(t, os.MkdirAll(pkg, 0755))
+	goMod := "// the module\nmodule example.com/m // comment\n\ngo 1.12\n"
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644))
+	src := "package pkg\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/m/internal/other\"\n)\n"
+	require.Nil(t, ioutil.WriteFile(filepath.Join(pkg, "pkg.go"), []byte(src), 0644))
+
+	modulePath, err := smgo.ModulePath(pkg)
+	require.Nil(t, err)
+	assert.Equal(t, "example.com/m", modulePath)
+
+	file, err := smgo.NewParser(smgo.WithImportSections(true)).ParseFile(context.Background(), filepath.Join(pkg, "pkg.go"), "UTF-8")
+	require.Nil(t, err)
+	importGroup := file.Children[1].(*smgo.Container)
+	require.Len(t, importGroup.Children, 2)
+	assert.Equal(t, "module-local", importGroup.Children[1].(*smgo.Container).Name)
+}
@@ -1,64 +1,158 @@
 package smgo
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"go/ast"
-	"go/parser"
+	"go/scanner"
 	"go/token"
+	"go/types"
 	"io"
-	"io/ioutil"
-	"sort"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/pkg/errors"
-	"golang.org/x/text/encoding/charmap"
 )
 
 var ErrUnsupportedEncoding = errors.New("Unsupported encoding")
 
-// Parse parses the GO source code from src and returns a *smgo.File declarations tree.
+// InternalError is returned by Parse when building the declarations tree fails unexpectedly. Decl and Offset identify
+// the top-level declaration being processed when the failure happened, if any.
+type InternalError struct {
+	Decl   string
+	Offset int
+	Cause  interface{}
+}
+
+func (e *InternalError) Error() string {
+	if e.Decl == "" {
+		return fmt.Sprintf("internal error: %v", e.Cause)
+	}
+	return fmt.Sprintf("internal error processing %s (offset %d): %v", e.Decl, e.Offset, e.Cause)
+}
+
+// Parser parses GO source code into declarations trees. It's configured when created and is safe for concurrent use.
+type Parser struct {
+	opts options
+}
+
+// NewParser returns a Parser with the given options.
+func NewParser(opts ...Option) *Parser {
+	p := &Parser{
+		opts: defaultOptions(),
+	}
+	for _, opt := range opts {
+		opt(&p.opts)
+	}
+	return p
+}
+
+var defaultParser = NewParser()
+
+// Parse parses the GO source code from src and returns a *smgo.File declarations tree. Spans and columns are in
+// characters (see Chars).
 func Parse(src io.Reader, encoding string) (*File, error) {
-	encoding = strings.ToUpper(encoding)
-	switch encoding {
-	case "UTF-8":
-	case "WINDOWS-1252":
-		decoder := charmap.Windows1252.NewDecoder()
-		src = decoder.Reader(src)
-	default:
-		return nil, ErrUnsupportedEncoding
+	return defaultParser.ParseReader(context.Background(), src, encoding)
+}
+
+// ParseFile parses the GO source code in the file path. With import sections, the module path is looked up in the
+// nearest go.mod if it isn't set.
+func (p *Parser) ParseFile(ctx context.Context, path string, encoding string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	opts := p.opts
+	if opts.importSections && opts.modulePath == "" {
+		opts.modulePath, err = ModulePath(filepath.Dir(path))
+		if err != nil {
+			return nil, errors.Wrap(err, "Error reading go.mod")
+		}
 	}
+	return parse(ctx, f, encoding, opts)
+}
+
+// ParseBytes parses the GO source code in src.
+func (p *Parser) ParseBytes(ctx context.Context, src []byte, encoding string) (*File, error) {
+	return p.ParseReader(ctx, bytes.NewReader(src), encoding)
+}
+
+// ParseReader parses the GO source code from src and returns a *smgo.File declarations tree. Parsing stops with
+// ctx.Err() when ctx is done.
+func (p *Parser) ParseReader(ctx context.Context, src io.Reader, encoding string) (*File, error) {
+	return parse(ctx, src, encoding, p.opts)
+}
+
+// parse parses the GO source code from src with opts.
+func parse(ctx context.Context, src io.Reader, encoding string, opts options) (file *File, err error) {
+	var v *visitor
+	defer func() {
+		if r := recover(); r != nil {
+			file, err = nil, newInternalError(v, r)
+		}
+	}()
 
-	srcBytes, err := ioutil.ReadAll(src)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	srcBytes, autoEncoding, decodingErrors, err := decode(src, encoding)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error reading src")
+		return nil, err
 	}
 
-	fset := token.NewFileSet()
-	fileAST, err := parser.ParseFile(fset, "", srcBytes, parser.ParseComments)
+	rounds := maxRecoveryRounds
+	if !opts.recovery {
+		rounds = 0
+	}
+	fset, fileAST, unparsed, parseErrors, err := parseFile(ctx, srcBytes, rounds)
 	if err != nil {
-		file := &File{
-			LocationSpan: LocationSpan{
-				Start: Location{1, 0},
-				End:   Location{1, 0},
-			},
-			FooterSpan: RuneSpan{0, -1},
-			ParsingErrors: []*ParsingError{
-				{
-					Location: Location{1, 0},
-					Message:  err.Error(),
-				},
-			},
+		if err == ctx.Err() {
+			return nil, err
 		}
-		return file, nil
+		return nil, errors.Wrap(err, "Error parsing src")
+	}
+	if packageClauseParsed(fset, fileAST, parseErrors) && (opts.recovery || len(parseErrors) == 0) {
+		v = newVisitor(fset, fileAST, srcBytes, opts)
+		file, err = v.buildFile(ctx, fileAST, srcBytes, unparsed, parseErrors)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		file = unparsedFile(fset, srcBytes, parseErrors)
+	}
+	file.AutoEncoding = autoEncoding
+	if len(decodingErrors) > 0 {
+		file.ParsingErrors = append(decodingErrors, file.ParsingErrors...)
 	}
 
-	// visit top-level declarations only
-	v := newVisitor(fset, fileAST)
-	for _, decl := range fileAST.Decls {
-		ast.Walk(v, decl)
+	if opts.unit == Chars {
+		newPositionMap(srcBytes).mapFile(file)
+	}
+	if opts.validation {
+		if violations := validate(file, srcBytes, opts.unit); len(violations) > 0 {
+			return nil, &ValidationError{violations}
+		}
+	}
+	return file, nil
+}
+
+// buildFile builds the declarations tree of fileAST.
+func (v *visitor) buildFile(ctx context.Context, fileAST *ast.File, srcBytes []byte, unparsed []region, parseErrors scanner.ErrorList) (*File, error) {
+	if v.File.Generated && v.opts.generated == GeneratedCoarse {
+		// a single node after the package clause
+		tokenFile := v.FileSet.File(fileAST.Pos())
+		start := packageClauseEnd(v.FileSet, fileAST, srcBytes)
+		v.addRegionNode(tokenFile, srcBytes, start, len(srcBytes), GeneratedNode, "generated")
+	} else if err := v.visitDecls(ctx, fileAST, srcBytes, unparsed, parseErrors); err != nil {
+		// visit top-level declarations only
+		return nil, err
 	}
 	// fix file LocationSpan
-	pos := v.FileSet.Position(token.Pos(1))
-	end := v.FileSet.Position(token.Pos(len(srcBytes)))
+	pos := v.FileSet.PositionFor(token.Pos(1), false)
+	end := v.FileSet.PositionFor(token.Pos(len(srcBytes)), false)
 	v.File.LocationSpan = LocationSpan{
 		Start: Location{
 			Line:   pos.Line,
@@ -76,38 +170,148 @@ func Parse(src io.Reader, encoding string) (*File, error) {
 	//	v.AddToParentContainer(c)
 	//}
 
-	err = fixBlockBoundaries(fset, v.File, srcBytes)
+	err := fixBlockBoundaries(v.FileSet, v.File, srcBytes, v.opts.debug)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error reading fixing boundaries")
 	}
 
+	disambiguateNames(v.File)
+	v.File.ParsingErrors = parsingErrors(v.FileSet, srcBytes, parseErrors)
+
 	return v.File, nil
 }
 
+func newInternalError(v *visitor, cause interface{}) *InternalError {
+	e := &InternalError{
+		Cause: cause,
+	}
+	if v != nil && v.decl != nil {
+		e.Decl = declName(v.decl)
+		e.Offset = v.FileSet.PositionFor(v.decl.Pos(), false).Offset
+	}
+	return e
+}
+
+// declName returns a short description of decl, like "func F" or "type T".
+func declName(decl ast.Decl) string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv != nil && len(d.Recv.List) > 0 {
+			return "func " + methodName(d.Recv.List[0].Type, d.Name.Name)
+		}
+		return "func " + d.Name.Name
+	case *ast.GenDecl:
+		if len(d.Specs) != 1 {
+			return d.Tok.String()
+		}
+		switch spec := d.Specs[0].(type) {
+		case *ast.TypeSpec:
+			return "type " + spec.Name.Name
+		case *ast.ValueSpec:
+			return d.Tok.String() + " " + joinNames(spec.Names)
+		case *ast.ImportSpec:
+			return "import " + spec.Path.Value
+		}
+		return d.Tok.String()
+	}
+	return "declaration"
+}
+
+// packageClauseParsed reports whether the package clause of fileAST was parsed without errors. The rest of the
+// file isn't parsed at all otherwise.
+func packageClauseParsed(fset *token.FileSet, fileAST *ast.File, parseErrors scanner.ErrorList) bool {
+	if fileAST == nil || !fileAST.Package.IsValid() || fileAST.Name == nil || fileAST.Name.Name == "" {
+		return false
+	}
+	nameEnd := fset.PositionFor(fileAST.Name.End(), false).Offset
+	for _, e := range parseErrors {
+		if e.Pos.Offset <= nameEnd {
+			return false
+		}
+	}
+	return true
+}
+
+// unparsedFile returns a File made of a single unparsed node covering the whole src.
+func unparsedFile(fset *token.FileSet, src []byte, parseErrors scanner.ErrorList) *File {
+	file := &File{
+		LocationSpan: LocationSpan{
+			Start: Location{1, 0},
+			End:   Location{1, 0},
+		},
+		FooterSpan:    RuneSpan{0, -1},
+		ParsingErrors: parsingErrors(fset, src, parseErrors),
+	}
+	if len(src) == 0 {
+		return file
+	}
+	end := fset.PositionFor(token.Pos(len(src)), false)
+	file.LocationSpan.End = Location{end.Line, end.Column}
+	locationSpan := file.LocationSpan
+	if bytes.HasSuffix(src, []byte("\r\n")) {
+		// like any other node, it ends at the start of the line terminator
+		locationSpan.End.Column--
+	}
+	file.AddNode(&Terminal{
+		Type:         UnparsedNode,
+		Name:         "unparsed",
+		LocationSpan: locationSpan,
+		Span:         RuneSpan{0, len(src) - 1},
+	})
+	return file
+}
+
+func parsingErrors(fset *token.FileSet, src []byte, parseErrors scanner.ErrorList) []*ParsingError {
+	if len(parseErrors) == 0 {
+		return nil
+	}
+	errs := make([]*ParsingError, 0, len(parseErrors))
+	for _, e := range parseErrors {
+		// the positions of the errors are adjusted by //line directives, recomputed from their offsets
+		offset := e.Pos.Offset
+		position := fset.PositionFor(token.Pos(offset+1), false)
+		column := position.Column - 1
+		if offset == len(src) && bytes.HasSuffix(src, []byte("\r\n")) ||
+			offset > 0 && offset < len(src) && src[offset] == '\n' && src[offset-1] == '\r' {
+			// errors found at (or after) a "\r\n" are reported as if it were a single character
+			column--
+		}
+		errs = append(errs, &ParsingError{
+			Location: Location{
+				Line:   position.Line,
+				Column: column,
+			},
+			Message: e.Msg,
+		})
+	}
+	return errs
+}
+
 type parentNode interface {
 	AddNode(node Node)
 	Nodes() []Node
 }
 
-type commentSet map[*ast.CommentGroup]struct{}
-
 type visitor struct {
 	FileSet        *token.FileSet
 	File           *File
-	Comments       commentSet
+	Comments       *commentCursor
 	astStack       []ast.Node
 	containerStack []parentNode
+	// decl is the top-level declaration being visited
+	decl ast.Decl
+	src  []byte
+	opts options
 }
 
-func newVisitor(fset *token.FileSet, srcAST *ast.File) *visitor {
+func newVisitor(fset *token.FileSet, srcAST *ast.File, src []byte, opts options) *visitor {
 	v := &visitor{
 		FileSet: fset,
+		src:     src,
+		opts:    opts,
 	}
 	// save comments to insert free-floating comments in the resulting File as Comment nodes.
-	v.Comments = make(commentSet, len(srcAST.Comments))
-	for _, cg := range srcAST.Comments {
-		v.Comments[cg] = struct{}{}
-	}
+	v.Comments = newCommentCursor(fset, srcAST.Comments)
 
 	file := v.createFile(srcAST)
 	v.File = file
@@ -133,13 +337,173 @@ func (v *visitor) Peek() (ast.Node, parentNode) {
 	return v.astStack[len(v.astStack)-1], v.containerStack[len(v.containerStack)-1]
 }
 
+// visitDecls visits the top-level declarations of fileAST. The regions masked while parsing, the declarations
+// still containing parsing errors, and any other broken region between the declarations parsed cleanly, are added
+// as unparsed nodes.
+func (v *visitor) visitDecls(ctx context.Context, fileAST *ast.File, src []byte, unparsed []region, parseErrors scanner.ErrorList) error {
+	tokenFile := v.FileSet.File(fileAST.Pos())
+	hasErrors := func(start, end int) bool {
+		for _, e := range parseErrors {
+			if e.Pos.Offset >= start && e.Pos.Offset < end {
+				return true
+			}
+		}
+		return false
+	}
+	addUnparsed := func(start, end int) {
+		if hasErrors(start, end) {
+			v.addUnparsed(tokenFile, src, start, end)
+			return
+		}
+		for _, r := range unparsed {
+			if r.Start >= start && r.End <= end {
+				v.addUnparsed(tokenFile, src, r.Start, r.End)
+			}
+		}
+	}
+	// the region after the last declaration parsed cleanly
+	regionStart := packageClauseEnd(v.FileSet, fileAST, src)
+	for _, decl := range fileAST.Decls {
+		start, end, ok := declBounds(tokenFile, src, decl)
+		if !ok || hasErrors(start, end+1) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		addUnparsed(regionStart, start)
+		v.decl = decl
+		if last := lastDirective(declDoc(decl)); last != nil {
+			v.walkWithDirectives(decl, last)
+		} else {
+			ast.Walk(v, decl)
+		}
+		v.decl = nil
+		regionStart = end
+	}
+	addUnparsed(regionStart, len(src)+1)
+	return nil
+}
+
+// declBounds returns the offsets of the start of decl (including its doc comment) and the end of its last line.
+func declBounds(tokenFile *token.File, src []byte, decl ast.Decl) (int, int, bool) {
+	if _, ok := decl.(*ast.BadDecl); ok {
+		return 0, 0, false
+	}
+	pos := decl.Pos()
+	switch d := decl.(type) {
+	case *ast.GenDecl:
+		if d.Doc != nil {
+			pos = d.Doc.Pos()
+		}
+	case *ast.FuncDecl:
+		if d.Doc != nil {
+			pos = d.Doc.Pos()
+		}
+	}
+	if !pos.IsValid() || !decl.End().IsValid() || decl.End() <= decl.Pos() {
+		return 0, 0, false
+	}
+	return tokenFile.Offset(pos), lineEnd(src, tokenFile.Offset(decl.End())), true
+}
+
+// packageClauseEnd returns the offset of the end of the line of the package clause, or of the line where the
+// comments following it on the same line end, like a /*-style comment spanning more lines.
+func packageClauseEnd(fset *token.FileSet, fileAST *ast.File, src []byte) int {
+	tokenFile := fset.File(fileAST.Pos())
+	end := tokenFile.Offset(fileAST.Name.End())
+	for _, cg := range fileAST.Comments {
+		start := tokenFile.Offset(cg.Pos())
+		if start < end {
+			continue
+		}
+		if tokenFile.PositionFor(cg.Pos(), false).Line != tokenFile.PositionFor(tokenFile.Pos(end), false).Line {
+			break
+		}
+		end = commentEnd(fset, src, cg)
+	}
+	return lineEnd(src, end)
+}
+
+// lineEnd returns the offset of the end of the line containing offset: the offset of the '\n', or len(src).
+func lineEnd(src []byte, offset int) int {
+	for offset < len(src) && src[offset] != '\n' {
+		offset++
+	}
+	return offset
+}
+
+// addUnparsed adds an unparsed node covering src[start:end], trimming the surrounding white space.
+func (v *visitor) addUnparsed(tokenFile *token.File, src []byte, start, end int) {
+	v.addRegionNode(tokenFile, src, start, end, UnparsedNode, "unparsed")
+}
+
+// addRegionNode adds a terminal covering src[start:end], trimming the surrounding white space.
+func (v *visitor) addRegionNode(tokenFile *token.File, src []byte, start, end int, nodeType NodeType, name string) {
+	if end > len(src) {
+		end = len(src)
+	}
+	for start < end && isSpace(src[start]) {
+		start++
+	}
+	for end > start && isSpace(src[end-1]) {
+		end--
+	}
+	if start == end {
+		return
+	}
+	v.Comments.consumeRange(start, end)
+	ffc := v.freeFloatingCommentsBefore(start)
+	v.AddFFCToParentContainer(ffc...)
+	pos := tokenFile.Pos(start)
+	endPos := tokenFile.Pos(end)
+	if end == len(src) {
+		endPos = tokenFile.Pos(end - 1)
+	}
+	v.AddToParentContainer(&Terminal{
+		Type:         nodeType,
+		Name:         name,
+		LocationSpan: locationSpanFromPositions(v.FileSet, pos, endPos),
+		Span:         runeSpanFromPositions(v.FileSet, pos, endPos),
+	})
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// inInterface reports whether the innermost struct or interface type being visited is an interface.
+func (v *visitor) inInterface() bool {
+	for i := len(v.astStack) - 1; i >= 0; i-- {
+		switch v.astStack[i].(type) {
+		case *ast.InterfaceType:
+			return true
+		case *ast.StructType:
+			return false
+		}
+	}
+	return false
+}
+
 func (v *visitor) AddToParentContainer(node ...Node) {
 	_, parentContainer := v.Peek()
 	for _, n := range node {
+		switch n := n.(type) {
+		case *Terminal:
+			v.consumeComments(n.Span)
+		case *Container:
+			v.consumeComments(n.HeaderSpan)
+		}
 		parentContainer.AddNode(n)
 	}
 }
 
+// consumeComments removes the comments starting inside span from the free-floating comments: they're part of a node,
+// like the comments in a function body.
+func (v *visitor) consumeComments(span RuneSpan) {
+	v.Comments.consumeRange(span.Start, span.End)
+}
+
 // merges last container with a free-floating comment, in cases like:
 // type (
 // ...
@@ -153,7 +517,7 @@ func mergeFFCToPrevContainer(lc Node, ffc *Terminal) bool {
 	switch lastChild := lc.(type) {
 	case *Container:
 		switch lastChild.Type {
-		case StructNode, InterfaceNode:
+		case StructNode, InterfaceNode, AliasNode:
 			if ffc.LocationSpan.Start.Line == lastChild.LocationSpan.End.Line {
 				lastChild.LocationSpan.End.Column = ffc.LocationSpan.End.Column
 				lastChild.FooterSpan.End = ffc.Span.End
@@ -230,16 +594,20 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 		switch node := astNode.(type) {
 		case *ast.GenDecl:
 			if node.Rparen.IsValid() {
-				position := v.FileSet.Position(node.Rparen)
+				position := v.FileSet.PositionFor(node.Rparen, false)
 				ffc := v.freeFloatingCommentsBefore(position.Offset)
 				v.AddFFCToParentContainer(ffc...)
+				if node.Tok == token.IMPORT && v.opts.importSections {
+					_, group := v.Peek()
+					v.splitImportSections(group.(*Container))
+				}
 			}
 		case *ast.StructType:
-			position := v.FileSet.Position(node.End())
+			position := v.FileSet.PositionFor(node.End(), false)
 			ffc := v.freeFloatingCommentsBefore(position.Offset)
 			v.AddFFCToParentContainer(ffc...)
 		case *ast.InterfaceType:
-			position := v.FileSet.Position(node.End())
+			position := v.FileSet.PositionFor(node.End(), false)
 			ffc := v.freeFloatingCommentsBefore(position.Offset)
 			v.AddFFCToParentContainer(ffc...)
 		}
@@ -281,6 +649,13 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 				if !ok {
 					panic("*ast.ValueSpec expected")
 				}
+				if preamble := cgoPreamble(n, is); preamble != nil {
+					container := v.createCgoPreamble(n, is, preamble)
+					ffc := v.freeFloatingCommentsBefore(container.HeaderSpan.Start)
+					v.AddFFCToParentContainer(ffc...)
+					v.AddToParentContainer(container)
+					return nil
+				}
 				importNode := v.createImport(n, is)
 				ffc := v.freeFloatingCommentsBefore(importNode.Span.Start)
 				v.AddFFCToParentContainer(ffc...)
@@ -303,6 +678,14 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 				if !ok {
 					panic("*ast.ValueSpec expected")
 				}
+				if fields, nodeType := anonymousType(vs.Type); fields != nil && v.opts.anonymousTypes {
+					container := v.createVarContainer(n, vs, fields, nodeType)
+					ffc := v.freeFloatingCommentsBefore(container.HeaderSpan.Start)
+					v.AddFFCToParentContainer(ffc...)
+					v.AddToParentContainer(container)
+					v.visitAnonymousType(vs, container)
+					return nil
+				}
 				varNode := v.createVar(n, vs)
 				ffc := v.freeFloatingCommentsBefore(varNode.Span.Start)
 				v.AddFFCToParentContainer(ffc...)
@@ -311,7 +694,7 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 			return nil
 		}
 	case *ast.ValueSpec:
-		parentASTNode, parentContainer := v.Peek()
+		parentASTNode, _ := v.Peek()
 		gd, ok := parentASTNode.(*ast.GenDecl)
 		if !ok {
 			panic("*ast.GenDecl expected")
@@ -321,15 +704,33 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 			constNode := v.createConstInGroup(n)
 			ffc := v.freeFloatingCommentsBefore(constNode.Span.Start)
 			v.AddFFCToParentContainer(ffc...)
-			parentContainer.AddNode(constNode)
+			v.AddToParentContainer(constNode)
 		case token.VAR:
+			if fields, nodeType := anonymousType(n.Type); fields != nil && v.opts.anonymousTypes {
+				container := v.createVarContainerInGroup(n, fields, nodeType)
+				ffc := v.freeFloatingCommentsBefore(container.HeaderSpan.Start)
+				v.AddFFCToParentContainer(ffc...)
+				v.AddToParentContainer(container)
+				v.visitAnonymousType(n, container)
+				return nil
+			}
 			varNode := v.createVarInGroup(n)
 			ffc := v.freeFloatingCommentsBefore(varNode.Span.Start)
 			v.AddFFCToParentContainer(ffc...)
-			parentContainer.AddNode(varNode)
+			v.AddToParentContainer(varNode)
 		}
 		return nil
 	case *ast.ImportSpec:
+		parentASTNode, _ := v.Peek()
+		if gd, ok := parentASTNode.(*ast.GenDecl); ok {
+			if preamble := cgoPreamble(gd, n); preamble != nil {
+				container := v.createCgoPreamble(gd, n, preamble)
+				ffc := v.freeFloatingCommentsBefore(container.HeaderSpan.Start)
+				v.AddFFCToParentContainer(ffc...)
+				v.AddToParentContainer(container)
+				return nil
+			}
+		}
 		importNode := v.createImportInGroup(n)
 		ffc := v.freeFloatingCommentsBefore(importNode.Span.Start)
 		v.AddFFCToParentContainer(ffc...)
@@ -347,7 +748,11 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 		if !ok {
 			panic("*ast.GenDecl expected")
 		}
-		switch n.Type.(type) {
+		typ := n.Type
+		if !v.opts.typeMembers {
+			typ = nil
+		}
+		switch typ.(type) {
 		case *ast.InterfaceType:
 			var container *Container
 			if gd.Lparen.IsValid() {
@@ -355,10 +760,10 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 			} else {
 				container = v.createInterface(gd, n)
 			}
-			ffc := v.freeFloatingCommentsBefore(container.HeaderSpan.Start)
-			if len(ffc) > 0 {
-
+			if n.Assign.IsValid() {
+				container.Type = AliasNode
 			}
+			ffc := v.freeFloatingCommentsBefore(container.HeaderSpan.Start)
 			v.AddFFCToParentContainer(ffc...)
 			v.AddToParentContainer(container)
 			v.Push(n, container)
@@ -370,6 +775,9 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 			} else {
 				container = v.createStruct(gd, n)
 			}
+			if n.Assign.IsValid() {
+				container.Type = AliasNode
+			}
 			ffc := v.freeFloatingCommentsBefore(container.HeaderSpan.Start)
 			v.AddFFCToParentContainer(ffc...)
 			v.AddToParentContainer(container)
@@ -382,17 +790,39 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 			} else {
 				terminal = v.createType(gd, n)
 			}
+			if fields, nodeType := anonymousType(n.Type); fields != nil {
+				terminal.Type = nodeType
+			}
+			if n.Assign.IsValid() {
+				terminal.Type = AliasNode
+			}
 			ffc := v.freeFloatingCommentsBefore(terminal.Span.Start)
 			v.AddFFCToParentContainer(ffc...)
 			v.AddToParentContainer(terminal)
 			return nil
 		}
 	case *ast.Field:
-		fieldNode := v.createField(n)
+		if fields, nodeType := anonymousType(n.Type); fields != nil && len(n.Names) > 0 && v.opts.anonymousTypes {
+			container := v.createFieldContainer(n, fields, nodeType)
+			ffc := v.freeFloatingCommentsBefore(container.HeaderSpan.Start)
+			v.AddFFCToParentContainer(ffc...)
+			v.AddToParentContainer(container)
+			v.visitAnonymousType(n, container)
+			return nil
+		}
+		fieldNode := v.createField(n, v.inInterface())
 		ffc := v.freeFloatingCommentsBefore(fieldNode.Span.Start)
 		v.AddFFCToParentContainer(ffc...)
 		v.AddToParentContainer(fieldNode)
 		return nil
+	case *ast.FieldList:
+		parentASTNode, container := v.Peek()
+		// type parameters are part of the header of generic types
+		if ts, ok := parentASTNode.(*ast.TypeSpec); ok && ts.TypeParams == n {
+			return nil
+		}
+		v.Push(n, container)
+		return v
 	default:
 		_, container := v.Peek()
 		v.Push(n, container)
@@ -400,40 +830,46 @@ func (v *visitor) Visit(node ast.Node) ast.Visitor {
 	}
 }
 
-func (v *visitor) freeFloatingCommentsBefore(offset int) []*Terminal {
-	cgNodes := make([]*ast.CommentGroup, 0, 5)
-	for cg, _ := range v.Comments {
-		cgPosition := v.FileSet.Position(cg.End())
-		if cgPosition.Offset < offset {
-			cgNodes = append(cgNodes, cg)
-		}
-	}
-	if len(cgNodes) > 1 {
-		sort.Slice(cgNodes, func(i, j int) bool {
-			posi := v.FileSet.Position(cgNodes[i].Pos())
-			posj := v.FileSet.Position(cgNodes[j].Pos())
-			return posi.Offset < posj.Offset
-		})
+// visitAnonymousType visits the fields of the anonymous struct or interface type declared by n (a var spec or a
+// field), adding them to container.
+func (v *visitor) visitAnonymousType(n ast.Node, container *Container) {
+	var typ ast.Expr
+	switch n := n.(type) {
+	case *ast.ValueSpec:
+		typ = n.Type
+	case *ast.Field:
+		typ = n.Type
 	}
+	v.Push(n, container)
+	ast.Walk(v, typ)
+	v.Pop()
+}
+
+// anonymousType returns the field list of an anonymous struct or interface type, and the type of container
+// used for it; nil if expr is any other type.
+func anonymousType(expr ast.Expr) (*ast.FieldList, NodeType) {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return t.Fields, StructNode
+	case *ast.InterfaceType:
+		return t.Methods, InterfaceNode
+	}
+	return nil, 0
+}
+
+func (v *visitor) freeFloatingCommentsBefore(offset int) []*Terminal {
+	cgNodes := v.Comments.before(offset)
 	comments := make([]*Terminal, 0, len(cgNodes))
 	for _, cg := range cgNodes {
-		delete(v.Comments, cg)
-		name := strings.TrimSpace(cg.Text())
-		if len(name) > 10 {
-			name = name[0:10] + "..."
-		}
-		comments = append(comments, &Terminal{
-			Type:         Comment,
-			Name:         name,
-			LocationSpan: locationSpanFromNode(v.FileSet, cg),
-			Span:         runeSpanFromNode(v.FileSet, cg),
-		})
+		// build constraints are only valid before the package clause, the File isn't created yet
+		comments = append(comments, v.commentNodes(cg, v.File == nil)...)
 	}
 	return comments
 }
 
 func (v *visitor) createFile(n *ast.File) *File {
 	f := &File{
+		Generated:    isGenerated(n),
 		LocationSpan: locationSpanFromNode(v.FileSet, n),
 		FooterSpan: RuneSpan{
 			Start: 0,
@@ -443,39 +879,42 @@ func (v *visitor) createFile(n *ast.File) *File {
 	pos := n.Pos()
 	if n.Doc != nil {
 		pos = n.Doc.Pos()
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
 	}
-	position := v.FileSet.Position(pos)
+	position := v.FileSet.PositionFor(pos, false)
 	ffc := v.freeFloatingCommentsBefore(position.Offset)
 	for _, c := range ffc {
 		f.AddNode(c)
 	}
 	end := n.Name.End()
-	f.AddNode(&Terminal{
+	packageNode := &Terminal{
 		Type:         PackageNode,
 		Name:         n.Name.Name,
 		LocationSpan: locationSpanFromPositions(v.FileSet, pos, end),
 		Span:         runeSpanFromPositions(v.FileSet, pos, end),
-	})
+	}
+	v.consumeComments(packageNode.Span)
+	f.AddNode(packageNode)
 	return f
 }
 
 func (v *visitor) createConst(gd *ast.GenDecl, n *ast.ValueSpec) *Terminal {
 	if gd.Doc != nil {
-		delete(v.Comments, gd.Doc)
+		v.Comments.consume(gd.Doc)
 	}
 	if n.Doc != nil {
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
 	}
 	pos := gd.Pos()
 	end := gd.End()
 	if n.Comment != nil {
 		end = n.Comment.End()
-		delete(v.Comments, n.Comment)
+		v.Comments.consume(n.Comment)
 	}
 	return &Terminal{
 		Type:         ConstNode,
-		Name:         n.Names[0].Name,
+		Name:         valueSpecName(token.CONST, n),
+		Names:        multipleNames(n.Names),
 		LocationSpan: locationSpanFromPositions(v.FileSet, pos, end),
 		Span:         runeSpanFromPositions(v.FileSet, pos, end),
 	}
@@ -483,7 +922,7 @@ func (v *visitor) createConst(gd *ast.GenDecl, n *ast.ValueSpec) *Terminal {
 
 func (v *visitor) createConstGroup(n *ast.GenDecl) *Container {
 	if n.Doc != nil {
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
 	}
 	c := &Container{
 		Type:         ConstNode,
@@ -500,17 +939,18 @@ func (v *visitor) createConstGroup(n *ast.GenDecl) *Container {
 
 func (v *visitor) createConstInGroup(n *ast.ValueSpec) *Terminal {
 	if n.Doc != nil {
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
 	}
 	pos := n.Pos()
 	end := n.End()
 	if n.Comment != nil {
 		end = n.Comment.End()
-		delete(v.Comments, n.Comment)
+		v.Comments.consume(n.Comment)
 	}
 	return &Terminal{
 		Type:         ConstNode,
-		Name:         n.Names[0].Name,
+		Name:         valueSpecName(token.CONST, n),
+		Names:        multipleNames(n.Names),
 		LocationSpan: locationSpanFromPositions(v.FileSet, pos, end),
 		Span:         runeSpanFromPositions(v.FileSet, pos, end),
 	}
@@ -518,47 +958,88 @@ func (v *visitor) createConstInGroup(n *ast.ValueSpec) *Terminal {
 
 func (v *visitor) createFunc(n *ast.FuncDecl) *Terminal {
 	if n.Doc != nil {
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
+	}
+	nodeType := FunctionNode
+	name := n.Name.Name
+	if n.Recv != nil && len(n.Recv.List) > 0 {
+		nodeType = MethodNode
+		name = methodName(n.Recv.List[0].Type, name)
 	}
 	return &Terminal{
-		Type:         FunctionNode,
-		Name:         n.Name.Name,
+		Type:         nodeType,
+		Name:         name,
 		LocationSpan: locationSpanFromNode(v.FileSet, n),
 		Span:         runeSpanFromNode(v.FileSet, n),
 	}
 }
 
+// methodName qualifies a method name with its receiver type, using the method expression syntax: T.Method or
+// (*T).Method. Type parameters of generic receivers are dropped, so (l *List[T]) Push becomes (*List).Push.
+func methodName(recv ast.Expr, name string) string {
+	recvType := receiverTypeName(recv)
+	if strings.HasPrefix(recvType, "*") {
+		return "(" + recvType + ")." + name
+	}
+	return recvType + "." + name
+}
+
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return "*" + receiverTypeName(t.X)
+	case *ast.ParenExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	default:
+		return types.ExprString(expr)
+	}
+}
+
 func (v *visitor) createImport(gd *ast.GenDecl, n *ast.ImportSpec) *Terminal {
 	if gd.Doc != nil {
-		delete(v.Comments, gd.Doc)
+		v.Comments.consume(gd.Doc)
 	}
 	if n.Doc != nil {
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
 	}
 	pos := n.Pos()
 	end := n.End()
 	if n.Comment != nil {
 		end = n.Comment.End()
-		delete(v.Comments, n.Comment)
-	}
-	var name string
-	switch n.Path.Kind {
-	case token.STRING:
-		name = n.Path.Value[1 : len(n.Path.Value)-1]
-	default:
-		panic("Unknown token type for import Path")
+		v.Comments.consume(n.Comment)
 	}
+	name, localName := importName(n)
 	return &Terminal{
 		Type:         ImportNode,
 		Name:         name,
 		LocationSpan: locationSpanFromPositions(v.FileSet, pos, end),
 		Span:         runeSpanFromPositions(v.FileSet, pos, end),
+		LocalName:    localName,
+	}
+}
+
+// importName returns the name of an import node: the path, preceded by the local name of the package (an alias, "."
+// or "_"), if any. The local name is returned too.
+func importName(n *ast.ImportSpec) (string, string) {
+	if n.Path.Kind != token.STRING {
+		panic("Unknown token type for import Path")
+	}
+	path := n.Path.Value[1 : len(n.Path.Value)-1]
+	if n.Name == nil {
+		return path, ""
 	}
+	return n.Name.Name + " " + path, n.Name.Name
 }
 
 func (v *visitor) createImportGroup(n *ast.GenDecl) *Container {
 	if n.Doc != nil {
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
 	}
 	c := &Container{
 		Type:         ImportNode,
@@ -575,26 +1056,21 @@ func (v *visitor) createImportGroup(n *ast.GenDecl) *Container {
 
 func (v *visitor) createImportInGroup(n *ast.ImportSpec) *Terminal {
 	if n.Doc != nil {
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
 	}
 	pos := n.Pos()
 	end := n.End()
 	if n.Comment != nil {
-		end = n.End()
-		delete(v.Comments, n.Comment)
-	}
-	var name string
-	switch n.Path.Kind {
-	case token.STRING:
-		name = n.Path.Value[1 : len(n.Path.Value)-1]
-	default:
-		panic("Unknown token type for import Path")
+		end = n.Comment.End()
+		v.Comments.consume(n.Comment)
 	}
+	name, localName := importName(n)
 	return &Terminal{
 		Type:         ImportNode,
 		Name:         name,
 		LocationSpan: locationSpanFromPositions(v.FileSet, pos, end),
 		Span:         runeSpanFromPositions(v.FileSet, pos, end),
+		LocalName:    localName,
 	}
 }
 
@@ -604,16 +1080,16 @@ func (v *visitor) createInterface(genDecl *ast.GenDecl, typeSpec *ast.TypeSpec)
 		panic("*ast.InterfaceType expected")
 	}
 	if genDecl.Doc != nil {
-		delete(v.Comments, genDecl.Doc)
+		v.Comments.consume(genDecl.Doc)
 	}
 	if typeSpec.Doc != nil {
-		delete(v.Comments, typeSpec.Doc)
+		v.Comments.consume(typeSpec.Doc)
 	}
 	pos := genDecl.Pos()
 	end := genDecl.End()
 	if typeSpec.Comment != nil {
 		end = typeSpec.Comment.End()
-		delete(v.Comments, typeSpec.Comment)
+		v.Comments.consume(typeSpec.Comment)
 	}
 	container := &Container{
 		Type:         InterfaceNode,
@@ -634,13 +1110,13 @@ func (v *visitor) createInterfaceInGroup(typeSpec *ast.TypeSpec) *Container {
 		panic("*ast.InterfaceType expected")
 	}
 	if typeSpec.Doc != nil {
-		delete(v.Comments, typeSpec.Doc)
+		v.Comments.consume(typeSpec.Doc)
 	}
 	pos := typeSpec.Pos()
 	end := st.Methods.Closing
 	if typeSpec.Comment != nil {
 		end = typeSpec.Comment.End()
-		delete(v.Comments, typeSpec.Comment)
+		v.Comments.consume(typeSpec.Comment)
 	}
 	container := &Container{
 		Type:         InterfaceNode,
@@ -661,16 +1137,16 @@ func (v *visitor) createStruct(genDecl *ast.GenDecl, typeSpec *ast.TypeSpec) *Co
 		panic("*ast.StructType expected")
 	}
 	if genDecl.Doc != nil {
-		delete(v.Comments, genDecl.Doc)
+		v.Comments.consume(genDecl.Doc)
 	}
 	if typeSpec.Doc != nil {
-		delete(v.Comments, typeSpec.Doc)
+		v.Comments.consume(typeSpec.Doc)
 	}
 	pos := genDecl.Pos()
 	end := genDecl.End()
 	if typeSpec.Comment != nil {
 		end = typeSpec.Comment.End()
-		delete(v.Comments, typeSpec.Comment)
+		v.Comments.consume(typeSpec.Comment)
 	}
 	container := &Container{
 		Type:         StructNode,
@@ -691,13 +1167,13 @@ func (v *visitor) createStructInGroup(typeSpec *ast.TypeSpec) *Container {
 		panic("*ast.StructType expected")
 	}
 	if typeSpec.Doc != nil {
-		delete(v.Comments, typeSpec.Doc)
+		v.Comments.consume(typeSpec.Doc)
 	}
 	pos := typeSpec.Pos()
 	end := st.Fields.Closing
 	if typeSpec.Comment != nil {
 		end = typeSpec.Comment.End()
-		delete(v.Comments, typeSpec.Comment)
+		v.Comments.consume(typeSpec.Comment)
 	}
 	container := &Container{
 		Type:         StructNode,
@@ -712,36 +1188,98 @@ func (v *visitor) createStructInGroup(typeSpec *ast.TypeSpec) *Container {
 	return container
 }
 
-func (v *visitor) createField(n *ast.Field) *Terminal {
+func (v *visitor) createField(n *ast.Field, inInterface bool) *Terminal {
 	if n.Doc != nil {
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
 	}
 	pos := n.Pos()
 	end := n.End()
 	if n.Comment != nil {
 		end = n.Comment.End()
-		delete(v.Comments, n.Comment)
+		v.Comments.consume(n.Comment)
+	}
+	nodeType := FieldNode
+	var name string
+	var names []string
+	if len(n.Names) == 0 {
+		// embedded fields and interfaces (and type set elements) are named after the embedded type
+		nodeType = EmbeddedFieldNode
+		if inInterface {
+			nodeType = EmbeddedInterfaceNode
+			if isTypeSetElement(n.Type) {
+				nodeType = TypeSetNode
+			}
+		}
+		name = types.ExprString(n.Type)
+	} else {
+		if _, ok := n.Type.(*ast.FuncType); ok && inInterface {
+			nodeType = MethodSpecNode
+		}
+		name = joinNames(n.Names)
+		if name == "_" {
+			// padding fields
+			name = "_ (_ " + types.ExprString(n.Type) + ")"
+		}
+		names = multipleNames(n.Names)
 	}
 	return &Terminal{
-		Type:         FieldNode,
-		Name:         n.Names[0].Name,
+		Type:         nodeType,
+		Name:         name,
+		Names:        names,
 		LocationSpan: locationSpanFromPositions(v.FileSet, pos, end),
 		Span:         runeSpanFromPositions(v.FileSet, pos, end),
 	}
 }
 
+// isTypeSetElement reports whether expr, embedded in an interface, can only be a type set element (~int,
+// int | string, []byte, ...) rather than an embedded interface.
+func isTypeSetElement(expr ast.Expr) bool {
+	switch t := expr.(type) {
+	case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr:
+		return false
+	case *ast.ParenExpr:
+		return isTypeSetElement(t.X)
+	default:
+		return true
+	}
+}
+
+func (v *visitor) createFieldContainer(n *ast.Field, fields *ast.FieldList, nodeType NodeType) *Container {
+	if n.Doc != nil {
+		v.Comments.consume(n.Doc)
+	}
+	pos := n.Pos()
+	end := n.End()
+	if n.Comment != nil {
+		end = n.Comment.End()
+		v.Comments.consume(n.Comment)
+	}
+	container := &Container{
+		Type:         nodeType,
+		Name:         joinNames(n.Names),
+		Names:        multipleNames(n.Names),
+		LocationSpan: locationSpanFromPositions(v.FileSet, pos, end),
+		HeaderSpan:   runeSpanFromPositions(v.FileSet, pos, fields.Opening),
+		FooterSpan:   runeSpanFromPositions(v.FileSet, fields.Closing, end),
+	}
+	if len(fields.List) > 0 {
+		container.Children = make([]Node, 0, len(fields.List))
+	}
+	return container
+}
+
 func (v *visitor) createType(genDecl *ast.GenDecl, n *ast.TypeSpec) *Terminal {
 	if genDecl.Doc != nil {
-		delete(v.Comments, genDecl.Doc)
+		v.Comments.consume(genDecl.Doc)
 	}
 	if n.Doc != nil {
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
 	}
 	pos := genDecl.Pos()
 	end := genDecl.End()
 	if n.Comment != nil {
 		end = n.Comment.End()
-		delete(v.Comments, n.Comment)
+		v.Comments.consume(n.Comment)
 	}
 	return &Terminal{
 		Type:         TypeNode,
@@ -753,7 +1291,7 @@ func (v *visitor) createType(genDecl *ast.GenDecl, n *ast.TypeSpec) *Terminal {
 
 func (v *visitor) createTypeGroup(n *ast.GenDecl) *Container {
 	if n.Doc != nil {
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
 	}
 	c := &Container{
 		Type:         TypeNode,
@@ -770,13 +1308,13 @@ func (v *visitor) createTypeGroup(n *ast.GenDecl) *Container {
 
 func (v *visitor) createTypeInGroup(n *ast.TypeSpec) *Terminal {
 	if n.Doc != nil {
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
 	}
 	pos := n.Pos()
 	end := n.End()
 	if n.Comment != nil {
 		end = n.Comment.End()
-		delete(v.Comments, n.Comment)
+		v.Comments.consume(n.Comment)
 	}
 	return &Terminal{
 		Type:         TypeNode,
@@ -788,20 +1326,21 @@ func (v *visitor) createTypeInGroup(n *ast.TypeSpec) *Terminal {
 
 func (v *visitor) createVar(gd *ast.GenDecl, n *ast.ValueSpec) *Terminal {
 	if gd.Doc != nil {
-		delete(v.Comments, gd.Doc)
+		v.Comments.consume(gd.Doc)
 	}
 	if n.Doc != nil {
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
 	}
 	pos := gd.Pos()
 	end := gd.End()
 	if n.Comment != nil {
 		end = n.Comment.End()
-		delete(v.Comments, n.Comment)
+		v.Comments.consume(n.Comment)
 	}
 	return &Terminal{
 		Type:         VarNode,
-		Name:         n.Names[0].Name,
+		Name:         valueSpecName(token.VAR, n),
+		Names:        multipleNames(n.Names),
 		LocationSpan: locationSpanFromPositions(v.FileSet, pos, end),
 		Span:         runeSpanFromPositions(v.FileSet, pos, end),
 	}
@@ -809,7 +1348,7 @@ func (v *visitor) createVar(gd *ast.GenDecl, n *ast.ValueSpec) *Terminal {
 
 func (v *visitor) createVarGroup(n *ast.GenDecl) *Container {
 	if n.Doc != nil {
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
 	}
 	c := &Container{
 		Type:         VarNode,
@@ -826,26 +1365,124 @@ func (v *visitor) createVarGroup(n *ast.GenDecl) *Container {
 
 func (v *visitor) createVarInGroup(n *ast.ValueSpec) *Terminal {
 	if n.Doc != nil {
-		delete(v.Comments, n.Doc)
+		v.Comments.consume(n.Doc)
 	}
 	pos := n.Pos()
 	end := n.End()
 	if n.Comment != nil {
 		end = n.Comment.End()
-		delete(v.Comments, n.Comment)
+		v.Comments.consume(n.Comment)
 	}
 	return &Terminal{
 		Type:         VarNode,
-		Name:         n.Names[0].Name,
+		Name:         valueSpecName(token.VAR, n),
+		Names:        multipleNames(n.Names),
 		LocationSpan: locationSpanFromPositions(v.FileSet, pos, end),
 		Span:         runeSpanFromPositions(v.FileSet, pos, end),
 	}
 }
 
+// valueSpecName returns the name of a const or var spec. Blank specs, like var _ io.Reader = (*T)(nil), are named
+// after the whole declaration to tell them apart: _ (var _ io.Reader = (*T)(nil)).
+func valueSpecName(tok token.Token, n *ast.ValueSpec) string {
+	name := joinNames(n.Names)
+	if name != "_" {
+		return name
+	}
+	decl := tok.String() + " _"
+	if n.Type != nil {
+		decl += " " + types.ExprString(n.Type)
+	}
+	if len(n.Values) > 0 {
+		values := make([]string, len(n.Values))
+		for i, value := range n.Values {
+			values[i] = types.ExprString(value)
+		}
+		decl += " = " + strings.Join(values, ", ")
+	}
+	return "_ (" + decl + ")"
+}
+
+// joinNames returns the name of a spec declaring one or more identifiers, like "a, b, c" for var a, b, c int.
+func joinNames(idents []*ast.Ident) string {
+	return strings.Join(identNames(idents), ", ")
+}
+
+// multipleNames returns the identifiers declared by a multi-name spec, nil if it declares just one.
+func multipleNames(idents []*ast.Ident) []string {
+	if len(idents) < 2 {
+		return nil
+	}
+	return identNames(idents)
+}
+
+func identNames(idents []*ast.Ident) []string {
+	names := make([]string, len(idents))
+	for i, ident := range idents {
+		names[i] = ident.Name
+	}
+	return names
+}
+
+func (v *visitor) createVarContainer(gd *ast.GenDecl, n *ast.ValueSpec, fields *ast.FieldList, nodeType NodeType) *Container {
+	if gd.Doc != nil {
+		v.Comments.consume(gd.Doc)
+	}
+	if n.Doc != nil {
+		v.Comments.consume(n.Doc)
+	}
+	pos := gd.Pos()
+	end := gd.End()
+	if n.Comment != nil {
+		end = n.Comment.End()
+		v.Comments.consume(n.Comment)
+	}
+	container := &Container{
+		Type:         nodeType,
+		Name:         valueSpecName(token.VAR, n),
+		Names:        multipleNames(n.Names),
+		LocationSpan: locationSpanFromPositions(v.FileSet, pos, end),
+		HeaderSpan:   runeSpanFromPositions(v.FileSet, pos, fields.Opening),
+		FooterSpan:   runeSpanFromPositions(v.FileSet, fields.Closing, end),
+	}
+	// the footer has the rest of the spec, like the value
+	v.consumeComments(container.FooterSpan)
+	if len(fields.List) > 0 {
+		container.Children = make([]Node, 0, len(fields.List))
+	}
+	return container
+}
+
+func (v *visitor) createVarContainerInGroup(n *ast.ValueSpec, fields *ast.FieldList, nodeType NodeType) *Container {
+	if n.Doc != nil {
+		v.Comments.consume(n.Doc)
+	}
+	pos := n.Pos()
+	end := n.End()
+	if n.Comment != nil {
+		end = n.Comment.End()
+		v.Comments.consume(n.Comment)
+	}
+	container := &Container{
+		Type:         nodeType,
+		Name:         valueSpecName(token.VAR, n),
+		Names:        multipleNames(n.Names),
+		LocationSpan: locationSpanFromPositions(v.FileSet, pos, end),
+		HeaderSpan:   runeSpanFromPositions(v.FileSet, pos, fields.Opening),
+		FooterSpan:   runeSpanFromPositions(v.FileSet, fields.Closing, end),
+	}
+	// the footer has the rest of the spec, like the value
+	v.consumeComments(container.FooterSpan)
+	if len(fields.List) > 0 {
+		container.Children = make([]Node, 0, len(fields.List))
+	}
+	return container
+}
+
 func locationFromPosition(fset *token.FileSet, pos token.Pos) Location {
 	return Location{
-		Line:   fset.Position(pos).Line,
-		Column: fset.Position(pos).Column,
+		Line:   fset.PositionFor(pos, false).Line,
+		Column: fset.PositionFor(pos, false).Column,
 	}
 }
 
@@ -865,14 +1502,14 @@ func locationSpanFromNode(fset *token.FileSet, n ast.Node) LocationSpan {
 
 func runeSpanFromNode(fset *token.FileSet, n ast.Node) RuneSpan {
 	return RuneSpan{
-		Start: fset.Position(n.Pos()).Offset,
-		End:   fset.Position(n.End()).Offset,
+		Start: fset.PositionFor(n.Pos(), false).Offset,
+		End:   fset.PositionFor(n.End(), false).Offset,
 	}
 }
 
 func runeSpanFromPositions(fset *token.FileSet, pos1, pos2 token.Pos) RuneSpan {
 	return RuneSpan{
-		Start: fset.Position(pos1).Offset,
-		End:   fset.Position(pos2).Offset,
+		Start: fset.PositionFor(pos1, false).Offset,
+		End:   fset.PositionFor(pos2, false).Offset,
 	}
 }
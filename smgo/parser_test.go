@@ -2,12 +2,19 @@ package smgo_test
 
 import (
 	"bytes"
+	"context"
+	"io/ioutil"
+	"path/filepath"
 	"strings"
 	"testing"
+	"unicode/utf16"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/jriquelme/SemanticMergeGO/smgo"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
 )
 
 func newLocationSpan(startLine, startColumn, endLine, endColumn int) smgo.LocationSpan {
@@ -25,21 +32,110 @@ func newLocationSpan(startLine, startColumn, endLine, endColumn int) smgo.Locati
 
 func TestParseErrUnsupportedEncoding(t *testing.T) {
 	t.Parallel()
-	if testing.Verbose() {
-		smgo.PrintBlocks = true
-	}
-
 	file, err := smgo.Parse(strings.NewReader("package main\n"), "ISO 8859-1")
 	assert.Nil(t, file)
 	assert.Equal(t, smgo.ErrUnsupportedEncoding, err)
 }
 
-func TestParseEmpty(t *testing.T) {
+func TestEncodingName(t *testing.T) {
 	t.Parallel()
-	if testing.Verbose() {
-		smgo.PrintBlocks = true
+	for _, names := range [][]string{
+		{"UTF-8", "utf8", "unicode-1-1-utf-8"},
+		{"ISO-8859-1", "latin1", "windows-1252"},
+		{"UTF-16LE", "utf-16"},
+	} {
+		expected, err := smgo.EncodingName(names[0])
+		require.Nil(t, err)
+		for _, name := range names[1:] {
+			canonical, err := smgo.EncodingName(name)
+			require.Nil(t, err)
+			assert.Equal(t, expected, canonical, name)
+		}
+	}
+	_, err := smgo.EncodingName("UTF-32LE")
+	assert.Equal(t, smgo.ErrUnsupportedEncoding, err)
+}
+
+func TestParseEncodings(t *testing.T) {
+	t.Parallel()
+
+	src := "package p\n\n// V is a value\nvar V = \"v\"\n"
+	expected, err := smgo.Parse(strings.NewReader(src), "UTF-8")
+	require.Nil(t, err)
+	utf16le := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder()
+	utf16be := unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder()
+	encode := func(encoder *encoding.Encoder, s string) string {
+		encoded, err := encoder.String(s)
+		require.Nil(t, err)
+		return encoded
 	}
 
+	cases := []struct {
+		Encoding string
+		Src      string
+	}{
+		{"utf-8", src},
+		{"utf8", src},
+		{"us-ascii", src},
+		{"iso-8859-1", src},
+		{"latin1", src},
+		{"windows-1252", src},
+		{"IBM437", src},
+		{"utf-16", encode(utf16le, src)},
+		{"utf-16le", encode(utf16le, src)},
+		{"UTF-16BE", encode(utf16be, src)},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.Encoding, func(t *testing.T) {
+			file, err := smgo.Parse(strings.NewReader(testCase.Src), testCase.Encoding)
+			require.Nil(t, err)
+			assert.Equal(t, expected, file)
+		})
+	}
+
+	for _, encoding := range []string{"ISO 8859-1", "UTF-7", "utf-32", ""} {
+		file, err := smgo.Parse(strings.NewReader(src), encoding)
+		assert.Nil(t, file)
+		assert.Equal(t, smgo.ErrUnsupportedEncoding, err, encoding)
+	}
+}
+
+func TestParseBOM(t *testing.T) {
+	t.Parallel()
+
+	src := "\uFEFFpackage p\n\nvar V = 1\n"
+	expected, err := smgo.Parse(strings.NewReader(src), "UTF-8")
+	require.Nil(t, err)
+	// the BOM belongs to the first node
+	assert.Equal(t, smgo.RuneSpan{0, 10}, expected.Children[0].(*smgo.Terminal).Span)
+	assertTiling(t, expected, []byte(src))
+	utf16le, err := unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder().String(src)
+	require.Nil(t, err)
+	utf16be, err := unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewEncoder().String(src)
+	require.Nil(t, err)
+
+	cases := []struct {
+		Name     string
+		Encoding string
+		Src      string
+	}{
+		{"utf-8 as windows-1252", "windows-1252", src},
+		{"utf-16le", "utf-16le", utf16le},
+		{"utf-16le as utf-8", "utf-8", utf16le},
+		{"utf-16be", "utf-16be", utf16be},
+		{"utf-16be as utf-16le", "utf-16le", utf16be},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			file, err := smgo.Parse(strings.NewReader(testCase.Src), testCase.Encoding)
+			require.Nil(t, err)
+			assert.Equal(t, expected, file)
+		})
+	}
+}
+
+func TestParseEmpty(t *testing.T) {
+	t.Parallel()
 	src := bytes.NewReader([]byte{})
 	file, err := smgo.Parse(src, "UTF-8")
 	assert.NotNil(t, file)
@@ -52,7 +148,7 @@ func TestParseEmpty(t *testing.T) {
 		ParsingErrors: []*smgo.ParsingError{
 			{
 				Location: smgo.Location{1, 0},
-				Message:  "1:1: expected 'package', found 'EOF'",
+				Message:  "expected 'package', found 'EOF'",
 			},
 		},
 	}, file)
@@ -60,3 +156,429 @@ func TestParseEmpty(t *testing.T) {
 		spew.Dump(t.Name(), file)
 	}
 }
+
+// assertTiling checks that the spans of file cover src from start to end, without gaps or overlaps. The spans must
+// be in characters.
+func assertTiling(t *testing.T, file *smgo.File, src []byte) {
+	assertTilingLength(t, file, len(utf16.Encode([]rune(string(src)))))
+	assert.Empty(t, smgo.Validate(file, src))
+}
+
+// assertTilingLength is like assertTiling, for a source of the given length in the unit used by file.
+func assertTilingLength(t *testing.T, file *smgo.File, length int) {
+	offset := 0
+	var walk func(nodes []smgo.Node)
+	walk = func(nodes []smgo.Node) {
+		for _, node := range nodes {
+			switch n := node.(type) {
+			case *smgo.Terminal:
+				assert.Equal(t, offset, n.Span.Start, "span start of %s", n.Name)
+				offset = n.Span.End + 1
+			case *smgo.Container:
+				assert.Equal(t, offset, n.HeaderSpan.Start, "header start of %s", n.Name)
+				offset = n.HeaderSpan.End + 1
+				walk(n.Children)
+				assert.Equal(t, offset, n.FooterSpan.Start, "footer start of %s", n.Name)
+				offset = n.FooterSpan.End + 1
+			}
+		}
+	}
+	walk(file.Children)
+	if offset < length {
+		assert.Equal(t, smgo.RuneSpan{offset, length - 1}, file.FooterSpan, "file footer")
+		offset = length
+	}
+	assert.Equal(t, length, offset, "end of file")
+}
+
+func TestFileFindNodes(t *testing.T) {
+	t.Parallel()
+
+	src := "package p\n\nvar a, b, c int\n\nvar (\n\td int\n\tb2, c2 = 1, 2\n)\n"
+	file, err := smgo.Parse(strings.NewReader(src), "UTF-8")
+	require.Nil(t, err)
+
+	for _, name := range []string{"a", "b", "c", "a, b, c"} {
+		nodes := file.FindNodes(name)
+		if assert.Len(t, nodes, 1, name) {
+			assert.Equal(t, "a, b, c", nodes[0].(*smgo.Terminal).Name)
+		}
+	}
+	nodes := file.FindNodes("c2")
+	if assert.Len(t, nodes, 1) {
+		assert.Equal(t, "b2, c2", nodes[0].(*smgo.Terminal).Name)
+	}
+	assert.Empty(t, file.FindNodes("z"))
+}
+
+func TestParseDuplicateNamesAreStable(t *testing.T) {
+	t.Parallel()
+
+	names := func(src string) []string {
+		file, err := smgo.Parse(strings.NewReader(src), "UTF-8")
+		require.Nil(t, err)
+		var names []string
+		for _, child := range file.Children {
+			if terminal, ok := child.(*smgo.Terminal); ok && terminal.Type == smgo.FunctionNode {
+				names = append(names, terminal.Name)
+			}
+		}
+		return names
+	}
+
+	src := "package p\n\nfunc init() {}\n\nfunc init() {}\n"
+	assert.Equal(t, []string{"init", "init#2"}, names(src))
+	src = "package p\n\nfunc a() {}\n\nfunc init() {}\n\nfunc b() {}\n\nfunc init() {}\n"
+	assert.Equal(t, []string{"a", "init", "b", "init#2"}, names(src))
+}
+
+func TestParseNoTrailingNewline(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		Name string
+		Src  string
+	}{
+		{"func", "package p\n\nfunc F() {\n}"},
+		{"struct", "package p\n\ntype T struct {\n\tA int\n}"},
+		{"var group", "package p\n\nvar (\n\tA = 1\n)"},
+		{"comment", "package p\n\nvar A = 1 // comment"},
+		{"package", "package p"},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			file, err := smgo.Parse(strings.NewReader(testCase.Src), "UTF-8")
+			require.Nil(t, err)
+			assert.Nil(t, file.ParsingErrors)
+			assertTiling(t, file, []byte(testCase.Src))
+			if t.Failed() {
+				spew.Dump(t.Name(), file)
+			}
+		})
+	}
+}
+
+func TestInternalError(t *testing.T) {
+	t.Parallel()
+
+	err := &smgo.InternalError{Cause: "boom"}
+	assert.Equal(t, "internal error: boom", err.Error())
+	err = &smgo.InternalError{Decl: "func F", Offset: 11, Cause: "boom"}
+	assert.Equal(t, "internal error processing func F (offset 11): boom", err.Error())
+
+	// a panic while building the tree is recovered, identifying the declaration
+	parser := smgo.NewParser(smgo.WithCommentNamer(func(text string) string {
+		panic("boom")
+	}))
+	src := "package p\n\nvar A = 1\n\ntype T struct {\n\tA int\n\n\t// free-floating\n\n\tB int\n}\n"
+	file, parseErr := parser.ParseBytes(context.Background(), []byte(src), "UTF-8")
+	assert.Nil(t, file)
+	require.IsType(t, &smgo.InternalError{}, parseErr)
+	assert.Equal(t, &smgo.InternalError{Decl: "type T", Offset: 22, Cause: "boom"}, parseErr)
+}
+
+func TestParseSpanUnitBytes(t *testing.T) {
+	t.Parallel()
+
+	src, err := ioutil.ReadFile("testdata/simple_nonascii.go")
+	require.Nil(t, err)
+	file, err := smgo.NewParser(smgo.WithSpanUnit(smgo.Bytes)).ParseBytes(context.Background(), src, "UTF-8")
+	require.Nil(t, err)
+	assertTilingLength(t, file, len(src))
+
+	// the spans and locations refer to the bytes of src
+	for _, node := range file.Children {
+		terminal, ok := node.(*smgo.Terminal)
+		if !ok || terminal.Type != smgo.ConstNode {
+			continue
+		}
+		assert.Equal(t, "挨拶", terminal.Name)
+		text := string(src[terminal.Span.Start : terminal.Span.End+1])
+		assert.Equal(t, "\n// 挨拶 は日本語の識別子です。\nconst 挨拶 = \"こんにちは\"\n", text)
+		assert.Equal(t, len("const 挨拶 = \"こんにちは\"")+1, terminal.LocationSpan.End.Column)
+	}
+}
+
+func TestParseWindows1252Chars(t *testing.T) {
+	t.Parallel()
+
+	// "package p\n\n// Año\nvar Año = 1\n" in Windows-1252, one byte per character
+	src := []byte("package p\n\n// A\xf1o\nvar A\xf1o = 1\n")
+	file, err := smgo.Parse(bytes.NewReader(src), "windows-1252")
+	require.Nil(t, err)
+	assertTilingLength(t, file, len(src))
+	if assert.Len(t, file.Children, 2) {
+		assert.Equal(t, &smgo.Terminal{
+			Type:         smgo.VarNode,
+			Name:         "Año",
+			LocationSpan: newLocationSpan(2, 0, 4, 12),
+			Span:         smgo.RuneSpan{10, 29},
+		}, file.Children[1])
+	}
+}
+
+// TestParseCRLF parses every testdata file with "\r\n" line terminators and compares the result with the original
+// one: the offsets move by one for each line above them, lines and columns don't change.
+func TestParseCRLF(t *testing.T) {
+	t.Parallel()
+
+	srcs, err := filepath.Glob("testdata/*.go*")
+	require.Nil(t, err)
+	require.NotEmpty(t, srcs)
+	for _, src := range srcs {
+		src := src
+		t.Run(filepath.Base(src), func(t *testing.T) {
+			lf, err := ioutil.ReadFile(src)
+			require.Nil(t, err)
+			crlf := bytes.Replace(lf, []byte("\n"), []byte("\r\n"), -1)
+
+			parser := smgo.NewParser(smgo.WithSpanUnit(smgo.Bytes))
+			expected, err := parser.ParseBytes(context.Background(), lf, "UTF-8")
+			require.Nil(t, err)
+			file, err := parser.ParseBytes(context.Background(), crlf, "UTF-8")
+			require.Nil(t, err)
+
+			// offset maps an exclusive offset of lf to crlf
+			offset := func(o int) int {
+				return o + bytes.Count(lf[:o], []byte("\n"))
+			}
+			span := func(s smgo.RuneSpan) smgo.RuneSpan {
+				return smgo.RuneSpan{offset(s.Start), offset(s.End+1) - 1}
+			}
+			var walk func(nodes []smgo.Node)
+			walk = func(nodes []smgo.Node) {
+				for _, node := range nodes {
+					switch n := node.(type) {
+					case *smgo.Terminal:
+						n.Span = span(n.Span)
+					case *smgo.Container:
+						n.HeaderSpan = span(n.HeaderSpan)
+						n.FooterSpan = span(n.FooterSpan)
+						walk(n.Children)
+					}
+				}
+			}
+			walk(expected.Children)
+			expected.FooterSpan = span(expected.FooterSpan)
+			// the file ends at its last byte, now the '\n' after the '\r'
+			if bytes.HasSuffix(lf, []byte("\n")) {
+				expected.LocationSpan.End.Column++
+			}
+
+			assert.Equal(t, expected, file)
+			assertTilingLength(t, file, len(crlf))
+		})
+	}
+}
+
+func TestParseLoneCR(t *testing.T) {
+	t.Parallel()
+
+	// Go doesn't end lines with a lone '\r', but it's allowed where no semicolon is needed
+	src := "package p\n\nvar (\r\tA = 1\n)\r"
+	file, err := smgo.Parse(strings.NewReader(src), "UTF-8")
+	require.Nil(t, err)
+	assertTiling(t, file, []byte(src))
+	if assert.Len(t, file.Children, 2) {
+		container := file.Children[1].(*smgo.Container)
+		assert.Equal(t, smgo.RuneSpan{10, 16}, container.HeaderSpan)
+		assert.Equal(t, smgo.RuneSpan{24, 25}, container.FooterSpan)
+	}
+}
+
+func TestParseAutoEncoding(t *testing.T) {
+	t.Parallel()
+
+	// valid UTF-8
+	src := "package p\n\n// Año\nvar Año = 1\n"
+	expected, err := smgo.Parse(strings.NewReader(src), "UTF-8")
+	require.Nil(t, err)
+	assert.Empty(t, expected.AutoEncoding)
+	file, err := smgo.Parse(strings.NewReader(src), "auto")
+	require.Nil(t, err)
+	assert.Equal(t, "UTF-8", file.AutoEncoding)
+	expected.AutoEncoding = "UTF-8"
+	assert.Equal(t, expected, file)
+
+	// Windows-1252
+	latin1 := "package p\n\n// A\xf1o\nvar A\xf1o = 1\n"
+	expected, err = smgo.Parse(strings.NewReader(latin1), "windows-1252")
+	require.Nil(t, err)
+	expected.ParsingErrors = []*smgo.ParsingError{
+		{
+			Location: smgo.Location{3, 4},
+			Message:  "invalid UTF-8 byte 0xF1, decoded as windows-1252",
+		},
+		{
+			Location: smgo.Location{4, 5},
+			Message:  "invalid UTF-8 byte 0xF1, decoded as windows-1252",
+		},
+	}
+	expected.AutoEncoding = "windows-1252"
+	file, err = smgo.Parse(strings.NewReader(latin1), "AUTO")
+	require.Nil(t, err)
+	assert.Equal(t, expected, file)
+
+	// configured fallback, 0xA4 is '€' in ISO-8859-15
+	file, err = smgo.Parse(strings.NewReader("package p\n\nconst Euro = \"\xa4\"\n"), "auto:ISO-8859-15")
+	require.Nil(t, err)
+	assert.Equal(t, []*smgo.ParsingError{
+		{
+			Location: smgo.Location{3, 14},
+			Message:  "invalid UTF-8 byte 0xA4, decoded as ISO-8859-15",
+		},
+	}, file.ParsingErrors)
+	assert.Equal(t, "ISO-8859-15", file.AutoEncoding)
+	decoded, err := smgo.Decode(strings.NewReader("const Euro = \"\xa4\""), "auto:ISO-8859-15")
+	require.Nil(t, err)
+	assert.Equal(t, "const Euro = \"€\"", string(decoded))
+
+	// the byte order mark wins
+	file, err = smgo.Parse(strings.NewReader("\xff\xfep\x00a\x00c\x00k\x00a\x00g\x00e\x00 \x00p\x00"), "auto")
+	require.Nil(t, err)
+	assert.Equal(t, "UTF-16LE", file.AutoEncoding)
+
+	file, err = smgo.Parse(strings.NewReader(src), "auto:unknown")
+	assert.Nil(t, file)
+	assert.Equal(t, smgo.ErrUnsupportedEncoding, err)
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	src := "package p\n\ntype T struct {\n\tA int\n}\n\n// end\n"
+	parse := func() *smgo.File {
+		file, err := smgo.Parse(strings.NewReader(src), "UTF-8")
+		require.Nil(t, err)
+		require.Empty(t, smgo.Validate(file, []byte(src)))
+		return file
+	}
+
+	cases := []struct {
+		Name     string
+		Break    func(file *smgo.File)
+		Expected []smgo.Violation
+	}{
+		{
+			Name: "gap",
+			Break: func(file *smgo.File) {
+				file.Children[1].(*smgo.Container).HeaderSpan.Start++
+			},
+			Expected: []smgo.Violation{
+				{"T", "header [11, 26] leaves a gap after 9"},
+				{"T", "start location [L:2 C:0] doesn't match offset 11, expected [L:3 C:0]"},
+			},
+		},
+		{
+			Name: "overlap",
+			Break: func(file *smgo.File) {
+				file.Children[1].(*smgo.Container).Children[0].(*smgo.Terminal).Span.Start--
+			},
+			Expected: []smgo.Violation{
+				{"A", "span [26, 33] overlaps the previous span, expected start 27"},
+				{"A", "start location [L:4 C:0] doesn't match offset 26, expected [L:3 C:15]"},
+			},
+		},
+		{
+			Name: "footer",
+			Break: func(file *smgo.File) {
+				file.FooterSpan.End--
+			},
+			Expected: []smgo.Violation{
+				{"", "footer [36, 42] doesn't cover the end of the file, expected [36, 43]"},
+			},
+		},
+		{
+			Name: "location",
+			Break: func(file *smgo.File) {
+				terminal := file.Children[0].(*smgo.Terminal)
+				terminal.LocationSpan.Start.Column = 1
+				terminal.LocationSpan.End.Line = 3
+			},
+			Expected: []smgo.Violation{
+				{"p", "start location [L:1 C:1] doesn't match offset 0, expected [L:1 C:0]"},
+				{"p", "end location [L:3 C:10] is outside [0, 9]"},
+			},
+		},
+	}
+	for _, testCase := range cases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			file := parse()
+			testCase.Break(file)
+			assert.Equal(t, testCase.Expected, smgo.Validate(file, []byte(src)))
+		})
+	}
+}
+
+func TestParseBuildConstraint(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		Src          string
+		ExpectedType smgo.NodeType
+		ExpectedName string
+	}{
+		{"//go:build linux || darwin\n\npackage p\n", smgo.BuildConstraintNode, "linux || darwin"},
+		{"// +build linux darwin\n// +build amd64\n\npackage p\n", smgo.BuildConstraintNode, "(linux || darwin) && amd64"},
+		{"//go:build ignore\n// +build whatever\n\npackage p\n", smgo.BuildConstraintNode, "ignore"},
+		{"//go:generate go run gen.go\n\npackage p\n", smgo.DirectiveNode, "go:generate"},
+		{"//export F\n\npackage p\n", smgo.DirectiveNode, "export"},
+		{"// go:build is just a comment\n\npackage p\n", smgo.Comment, "go:build i..."},
+	}
+	for _, testCase := range cases {
+		file, err := smgo.Parse(strings.NewReader(testCase.Src), "UTF-8")
+		require.Nil(t, err)
+		require.Len(t, file.Children, 2, testCase.Src)
+		terminal := file.Children[0].(*smgo.Terminal)
+		assert.Equal(t, testCase.ExpectedType, terminal.Type, testCase.Src)
+		assert.Equal(t, testCase.ExpectedName, terminal.Name, testCase.Src)
+		assertTiling(t, file, []byte(testCase.Src))
+	}
+}
+
+func TestParseCgoDeclNames(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		Preamble string
+		Expected []string
+	}{
+		{"#include \"x.h\"\n#cgo linux,amd64 CFLAGS: -DX=1\n", []string{"#include \"x.h\"", "#cgo linux,amd64 CFLAGS"}},
+		{"#define MAX(a, b) \\\n\t((a) > (b) ? (a) : (b))\n#ifdef DEBUG\n#endif\n", []string{"#define MAX", "#ifdef DEBUG", "#endif"}},
+		{"int counter = 0;\nextern void callback(int);\nstatic char buf[64];\n", []string{"counter", "callback", "buf"}},
+		{"typedef int (*handler)(void *);\ntypedef unsigned long size;\n", []string{"handler", "size"}},
+		{"struct node {\n\tstruct node *next;\n};\n", []string{"node"}},
+		{"static int unterminated(void) {\n", []string{"unterminated"}},
+	}
+	for _, testCase := range cases {
+		src := "package p\n\n/*\n" + testCase.Preamble + "*/\nimport \"C\"\n"
+		file, err := smgo.Parse(strings.NewReader(src), "UTF-8")
+		require.Nil(t, err)
+		require.Len(t, file.Children, 2)
+		preamble := file.Children[1].(*smgo.Container)
+		require.Equal(t, smgo.CgoPreambleNode, preamble.Type)
+		var names []string
+		for _, child := range preamble.Children {
+			names = append(names, child.(*smgo.Terminal).Name)
+		}
+		assert.Equal(t, testCase.Expected, names, testCase.Preamble)
+		assertTiling(t, file, []byte(src))
+	}
+}
+
+func TestParseLineDirectives(t *testing.T) {
+	t.Parallel()
+
+	src := "package p\n\n//line parser.y:100\nfunc F() {\n}\n\n/*line parser.y:200:5*/ var V = \n"
+	file, err := smgo.Parse(strings.NewReader(src), "UTF-8")
+	require.Nil(t, err)
+	// locations are in src, not in parser.y
+	require.Len(t, file.Children, 3)
+	// a //line directive doesn't govern the declaration
+	assert.Equal(t, newLocationSpan(2, 0, 5, 2), file.Children[1].(*smgo.Terminal).LocationSpan)
+	assert.Equal(t, newLocationSpan(6, 0, 7, 32), file.Children[2].(*smgo.Terminal).LocationSpan)
+	if assert.NotEmpty(t, file.ParsingErrors) {
+		assert.Equal(t, smgo.Location{Line: 7, Column: 33}, file.ParsingErrors[0].Location)
+	}
+	assertTiling(t, file, []byte(src))
+}
@@ -0,0 +1,117 @@
+package smgo
+
+import (
+	"unicode/utf8"
+)
+
+// SpanUnit is the unit of the offsets in spans and of the columns in locations.
+type SpanUnit int
+
+const (
+	// Chars counts UTF-16 code units of the decoded source, which are the characters SemanticMerge works with. A
+	// Windows-1252 byte or a BOM is a single character, a character outside the BMP takes two.
+	Chars SpanUnit = iota
+	// Bytes counts bytes of the source decoded as UTF-8.
+	Bytes
+)
+
+// positionMap converts byte offsets and columns of the decoded source into UTF-16 code units.
+type positionMap struct {
+	// units[i] is the number of code units before the byte offset i, len(units) == len(src)+1
+	units []int
+	// lineStarts[i] is the byte offset of the line i+1
+	lineStarts []int
+}
+
+func newPositionMap(src []byte) *positionMap {
+	m := &positionMap{
+		units:      make([]int, len(src)+1),
+		lineStarts: []int{0},
+	}
+	n := 0
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRune(src[i:])
+		for j := 0; j < size; j++ {
+			m.units[i+j] = n
+		}
+		if r >= 0x10000 {
+			// surrogate pair
+			n += 2
+		} else {
+			n++
+		}
+		if r == '\n' {
+			m.lineStarts = append(m.lineStarts, i+1)
+		}
+		i += size
+	}
+	m.units[len(src)] = n
+	return m
+}
+
+func (m *positionMap) offset(offset int) int {
+	if offset < 0 {
+		return offset
+	}
+	if offset >= len(m.units) {
+		offset = len(m.units) - 1
+	}
+	return m.units[offset]
+}
+
+// span maps s, keeping the end inclusive: the end is the last code unit of the last character.
+func (m *positionMap) span(s RuneSpan) RuneSpan {
+	return RuneSpan{
+		Start: m.offset(s.Start),
+		End:   m.offset(s.End+1) - 1,
+	}
+}
+
+// column maps a column of line. Start columns are 0-based, end columns are 1-based (they point to the character
+// after the node).
+func (m *positionMap) column(line, column int, oneBased bool) int {
+	if line < 1 || line > len(m.lineStarts) || oneBased && column < 1 {
+		return column
+	}
+	start := m.lineStarts[line-1]
+	offset := start + column
+	if oneBased {
+		offset--
+	}
+	column = m.offset(offset) - m.offset(start)
+	if oneBased {
+		column++
+	}
+	return column
+}
+
+func (m *positionMap) locationSpan(ls LocationSpan) LocationSpan {
+	ls.Start.Column = m.column(ls.Start.Line, ls.Start.Column, false)
+	ls.End.Column = m.column(ls.End.Line, ls.End.Column, true)
+	return ls
+}
+
+// mapFile converts all the spans and locations of file.
+func (m *positionMap) mapFile(file *File) {
+	file.LocationSpan = m.locationSpan(file.LocationSpan)
+	file.FooterSpan = m.span(file.FooterSpan)
+	m.mapNodes(file.Children)
+	for _, e := range file.ParsingErrors {
+		e.Location.Column = m.column(e.Location.Line, e.Location.Column, false)
+	}
+}
+
+func (m *positionMap) mapNodes(nodes []Node) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *Terminal:
+			n.LocationSpan = m.locationSpan(n.LocationSpan)
+			n.Span = m.span(n.Span)
+		case *Container:
+			n.LocationSpan = m.locationSpan(n.LocationSpan)
+			n.HeaderSpan = m.span(n.HeaderSpan)
+			n.FooterSpan = m.span(n.FooterSpan)
+			m.mapNodes(n.Children)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package smgo
+
+import (
+	"bytes"
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+)
+
+// maxRecoveryRounds limits how many broken regions are masked before giving up on the rest of the file.
+const maxRecoveryRounds = 100
+
+// region is a range of offsets [Start, End) of the source.
+type region struct {
+	Start int
+	End   int
+}
+
+// parseFile parses src reporting all the errors found. go/parser tends to swallow the rest of the file after a
+// syntax error, so the top-level declaration containing the first error is masked with white space (keeping the
+// offsets of everything else) and the source is parsed again, until it parses cleanly. It returns the FileSet and
+// AST of the masked source, the masked regions and the errors found. At most rounds regions are masked, and parsing
+// stops with ctx.Err() when ctx is done.
+func parseFile(ctx context.Context, src []byte, rounds int) (*token.FileSet, *ast.File, []region, scanner.ErrorList, error) {
+	var masked []byte
+	var regions []region
+	var parseErrors scanner.ErrorList
+	for round := 0; ; round++ {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, nil, nil, err
+		}
+		input := src
+		if masked != nil {
+			input = masked
+		}
+		fset := token.NewFileSet()
+		fileAST, err := parser.ParseFile(fset, "", input, parser.ParseComments|parser.AllErrors)
+		if err == nil {
+			return fset, fileAST, regions, parseErrors, nil
+		}
+		errorList, ok := err.(scanner.ErrorList)
+		if !ok {
+			return nil, nil, nil, nil, err
+		}
+		errorList = dropCascadingErrors(input, errorList)
+		if round == rounds || !packageClauseParsed(fset, fileAST, errorList) {
+			return fset, fileAST, regions, append(parseErrors, errorList...), nil
+		}
+		packageEnd := packageClauseEnd(fset, fileAST, input)
+		tokenFile := fset.File(fileAST.Pos())
+		r := brokenRegion(input, tokenFile, fileAST.Decls, packageEnd, errorList[0].Pos.Offset)
+		if r.Start == r.End || isBlank(input[r.Start:r.End]) {
+			// no progress
+			return fset, fileAST, regions, append(parseErrors, errorList...), nil
+		}
+		for _, e := range errorList {
+			if e.Pos.Offset >= r.Start && e.Pos.Offset <= r.End {
+				parseErrors = append(parseErrors, e)
+			}
+		}
+		if masked == nil {
+			masked = make([]byte, len(src))
+			copy(masked, src)
+		}
+		for i := r.Start; i < r.End; i++ {
+			if masked[i] != '\n' {
+				masked[i] = ' '
+			}
+		}
+		regions = addRegion(regions, r)
+	}
+}
+
+// dropCascadingErrors keeps only the first error found at every position of errs, the others follow from it. go/parser
+// sorts the errors at the same position by message, so the first one is found parsing src again without
+// parser.AllErrors, which reports the first error of every line (up to 10).
+func dropCascadingErrors(src []byte, errs scanner.ErrorList) scanner.ErrorList {
+	_, err := parser.ParseFile(token.NewFileSet(), "", src, parser.ParseComments)
+	firstErrors, ok := err.(scanner.ErrorList)
+	if !ok {
+		return errs
+	}
+	first := make(map[int]string, len(firstErrors))
+	for _, e := range firstErrors {
+		first[e.Pos.Offset] = e.Msg
+	}
+	kept := errs[:0:0]
+	for i, e := range errs {
+		atSamePosition := i > 0 && errs[i-1].Pos.Offset == e.Pos.Offset ||
+			i+1 < len(errs) && errs[i+1].Pos.Offset == e.Pos.Offset
+		if msg, ok := first[e.Pos.Offset]; ok && atSamePosition && msg != e.Msg {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+// brokenRegion returns the region of the top-level declaration containing offset, from its start (or the end of
+// the previous declaration, when offset isn't part of any declaration) to the next top-level declaration keyword.
+func brokenRegion(src []byte, tokenFile *token.File, decls []ast.Decl, minStart, offset int) region {
+	if offset > len(src) {
+		offset = len(src)
+	}
+	start := minStart
+	for _, decl := range decls {
+		declStart, declEnd, ok := declBounds(tokenFile, src, decl)
+		if !ok {
+			if _, bad := decl.(*ast.BadDecl); !bad || tokenFile.Offset(decl.Pos()) > offset {
+				continue
+			}
+			declStart = tokenFile.Offset(decl.Pos())
+			declEnd = lineEnd(src, tokenFile.Offset(decl.End()))
+		}
+		if declStart > offset {
+			break
+		}
+		if declEnd < offset {
+			start = declEnd
+			continue
+		}
+		start = declStart
+		break
+	}
+	if start > offset {
+		start = lineStart(src, offset)
+	}
+
+	end := lineEnd(src, offset)
+	for end < len(src) {
+		next := end + 1
+		if isDeclLine(src[next:]) {
+			end = docStart(src, lineEnd(src, offset), next)
+			break
+		}
+		end = lineEnd(src, next)
+	}
+	return region{start, end}
+}
+
+// docStart returns the start of the // comment lines immediately preceding the line starting at offset.
+func docStart(src []byte, minStart, offset int) int {
+	for offset > minStart {
+		prev := lineStart(src, offset-1)
+		if prev < minStart || !bytes.HasPrefix(bytes.TrimLeft(src[prev:offset], " \t"), []byte("//")) {
+			break
+		}
+		offset = prev
+	}
+	return offset
+}
+
+var declKeywords = [][]byte{
+	[]byte("func"),
+	[]byte("type"),
+	[]byte("var"),
+	[]byte("const"),
+	[]byte("import"),
+}
+
+// isDeclLine reports whether line starts with a top-level declaration keyword.
+func isDeclLine(line []byte) bool {
+	for _, keyword := range declKeywords {
+		if bytes.HasPrefix(line, keyword) && len(line) > len(keyword) {
+			switch line[len(keyword)] {
+			case ' ', '\t', '(':
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lineStart returns the offset of the start of the line containing offset.
+func lineStart(src []byte, offset int) int {
+	if offset > len(src) {
+		offset = len(src)
+	}
+	for offset > 0 && src[offset-1] != '\n' {
+		offset--
+	}
+	return offset
+}
+
+func isBlank(b []byte) bool {
+	return len(bytes.TrimSpace(b)) == 0
+}
+
+// addRegion adds r to the sorted list of regions.
+func addRegion(regions []region, r region) []region {
+	i := len(regions)
+	for i > 0 && regions[i-1].Start > r.Start {
+		i--
+	}
+	regions = append(regions, region{})
+	copy(regions[i+1:], regions[i:])
+	regions[i] = r
+	return regions
+}
@@ -1,7 +1,8 @@
 package smgo_test
 
 import (
-	"os"
+	"context"
+	"io/ioutil"
 	"strings"
 	"testing"
 
@@ -13,14 +14,375 @@ import (
 
 func TestParseSimpleCases(t *testing.T) {
 	t.Parallel()
-	if testing.Verbose() {
-		smgo.PrintBlocks = true
-	}
+	parser := newTestParser()
 
 	simpleCases := []struct {
 		Src          string
 		ExpectedFile *smgo.File
 	}{
+		{
+			Src: "simple_alias.go",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 20, 2),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "simplealias",
+						LocationSpan: newLocationSpan(1, 0, 1, 20),
+						Span:         smgo.RuneSpan{0, 19},
+					},
+					&smgo.Terminal{
+						Type:         smgo.ImportNode,
+						Name:         "io",
+						LocationSpan: newLocationSpan(2, 0, 3, 12),
+						Span:         smgo.RuneSpan{20, 32},
+					},
+					&smgo.Terminal{
+						Type:         smgo.AliasNode,
+						Name:         "Reader",
+						LocationSpan: newLocationSpan(4, 0, 5, 24),
+						Span:         smgo.RuneSpan{33, 57},
+					},
+					&smgo.Terminal{
+						Type:         smgo.AliasNode,
+						Name:         "Set",
+						LocationSpan: newLocationSpan(6, 0, 7, 40),
+						Span:         smgo.RuneSpan{58, 98},
+					},
+					&smgo.Container{
+						Type:         smgo.AliasNode,
+						Name:         "Point",
+						LocationSpan: newLocationSpan(8, 0, 11, 2),
+						HeaderSpan:   smgo.RuneSpan{99, 121},
+						FooterSpan:   smgo.RuneSpan{132, 133},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.FieldNode,
+								Name:         "X, Y",
+								LocationSpan: newLocationSpan(10, 0, 10, 10),
+								Span:         smgo.RuneSpan{122, 131},
+								Names:        []string{"X", "Y"},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.AliasNode,
+						Name:         "Closer",
+						LocationSpan: newLocationSpan(12, 0, 15, 2),
+						HeaderSpan:   smgo.RuneSpan{134, 160},
+						FooterSpan:   smgo.RuneSpan{176, 177},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.MethodSpecNode,
+								Name:         "Close",
+								LocationSpan: newLocationSpan(14, 0, 14, 15),
+								Span:         smgo.RuneSpan{161, 175},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.TypeNode,
+						Name:         "type",
+						LocationSpan: newLocationSpan(16, 0, 20, 2),
+						HeaderSpan:   smgo.RuneSpan{178, 185},
+						FooterSpan:   smgo.RuneSpan{214, 215},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.TypeNode,
+								Name:         "Defined",
+								LocationSpan: newLocationSpan(18, 0, 18, 13),
+								Span:         smgo.RuneSpan{186, 198},
+							},
+							&smgo.Terminal{
+								Type:         smgo.AliasNode,
+								Name:         "Alias",
+								LocationSpan: newLocationSpan(19, 0, 19, 15),
+								Span:         smgo.RuneSpan{199, 213},
+							},
+						},
+					},
+				},
+				ParsingErrors: nil,
+			},
+		},
+		{
+			Src: "simple_anonymous.go",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 34, 2),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "simpleanonymous",
+						LocationSpan: newLocationSpan(1, 0, 1, 24),
+						Span:         smgo.RuneSpan{0, 23},
+					},
+					&smgo.Container{
+						Type:         smgo.StructNode,
+						Name:         "Server",
+						LocationSpan: newLocationSpan(2, 0, 15, 2),
+						HeaderSpan:   smgo.RuneSpan{24, 45},
+						FooterSpan:   smgo.RuneSpan{199, 200},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.FieldNode,
+								Name:         "Name",
+								LocationSpan: newLocationSpan(4, 0, 4, 15),
+								Span:         smgo.RuneSpan{46, 60},
+							},
+							&smgo.Container{
+								Type:         smgo.StructNode,
+								Name:         "Config",
+								LocationSpan: newLocationSpan(5, 0, 11, 3),
+								HeaderSpan:   smgo.RuneSpan{61, 77},
+								FooterSpan:   smgo.RuneSpan{156, 158},
+								Children: []smgo.Node{
+									&smgo.Terminal{
+										Type:         smgo.FieldNode,
+										Name:         "Host",
+										LocationSpan: newLocationSpan(6, 0, 6, 14),
+										Span:         smgo.RuneSpan{78, 91},
+									},
+									&smgo.Terminal{
+										Type:         smgo.FieldNode,
+										Name:         "Port",
+										LocationSpan: newLocationSpan(7, 0, 7, 11),
+										Span:         smgo.RuneSpan{92, 102},
+									},
+									&smgo.Container{
+										Type:         smgo.StructNode,
+										Name:         "TLS",
+										LocationSpan: newLocationSpan(8, 0, 10, 17),
+										HeaderSpan:   smgo.RuneSpan{103, 118},
+										FooterSpan:   smgo.RuneSpan{139, 155},
+										Children: []smgo.Node{
+											&smgo.Terminal{
+												Type:         smgo.FieldNode,
+												Name:         "Cert, Key",
+												LocationSpan: newLocationSpan(9, 0, 9, 20),
+												Span:         smgo.RuneSpan{119, 138},
+												Names:        []string{"Cert", "Key"},
+											},
+										},
+									},
+								},
+							},
+							&smgo.Container{
+								Type:         smgo.InterfaceNode,
+								Name:         "Handler",
+								LocationSpan: newLocationSpan(12, 0, 14, 3),
+								HeaderSpan:   smgo.RuneSpan{159, 179},
+								FooterSpan:   smgo.RuneSpan{196, 198},
+								Children: []smgo.Node{
+									&smgo.Terminal{
+										Type:         smgo.MethodSpecNode,
+										Name:         "Serve",
+										LocationSpan: newLocationSpan(13, 0, 13, 16),
+										Span:         smgo.RuneSpan{180, 195},
+									},
+								},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.StructNode,
+						Name:         "opts",
+						LocationSpan: newLocationSpan(16, 0, 19, 2),
+						HeaderSpan:   smgo.RuneSpan{201, 219},
+						FooterSpan:   smgo.RuneSpan{234, 235},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.FieldNode,
+								Name:         "Verbose",
+								LocationSpan: newLocationSpan(18, 0, 18, 14),
+								Span:         smgo.RuneSpan{220, 233},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.VarNode,
+						Name:         "var",
+						LocationSpan: newLocationSpan(20, 0, 24, 2),
+						HeaderSpan:   smgo.RuneSpan{236, 242},
+						FooterSpan:   smgo.RuneSpan{275, 276},
+						Children: []smgo.Node{
+							&smgo.Container{
+								Type:         smgo.StructNode,
+								Name:         "a, b",
+								LocationSpan: newLocationSpan(22, 0, 22, 22),
+								HeaderSpan:   smgo.RuneSpan{243, 255},
+								FooterSpan:   smgo.RuneSpan{263, 264},
+								Names:        []string{"a", "b"},
+								Children: []smgo.Node{
+									&smgo.Terminal{
+										Type:         smgo.FieldNode,
+										Name:         "X",
+										LocationSpan: newLocationSpan(22, 13, 22, 20),
+										Span:         smgo.RuneSpan{256, 262},
+									},
+								},
+							},
+							&smgo.Terminal{
+								Type:         smgo.VarNode,
+								Name:         "c",
+								LocationSpan: newLocationSpan(23, 0, 23, 10),
+								Span:         smgo.RuneSpan{265, 274},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.InterfaceNode,
+						Name:         "x",
+						LocationSpan: newLocationSpan(25, 0, 28, 4),
+						HeaderSpan:   smgo.RuneSpan{277, 293},
+						FooterSpan:   smgo.RuneSpan{299, 333},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.MethodSpecNode,
+								Name:         "m",
+								LocationSpan: newLocationSpan(26, 16, 26, 21),
+								Span:         smgo.RuneSpan{294, 298},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.VarNode,
+						Name:         "var#2",
+						LocationSpan: newLocationSpan(29, 0, 34, 2),
+						HeaderSpan:   smgo.RuneSpan{334, 340},
+						FooterSpan:   smgo.RuneSpan{392, 393},
+						Children: []smgo.Node{
+							&smgo.Container{
+								Type:         smgo.StructNode,
+								Name:         "y",
+								LocationSpan: newLocationSpan(31, 0, 33, 5),
+								HeaderSpan:   smgo.RuneSpan{341, 350},
+								FooterSpan:   smgo.RuneSpan{358, 391},
+								Children: []smgo.Node{
+									&smgo.Terminal{
+										Type:         smgo.FieldNode,
+										Name:         "A",
+										LocationSpan: newLocationSpan(31, 10, 31, 17),
+										Span:         smgo.RuneSpan{351, 357},
+									},
+								},
+							},
+						},
+					},
+				},
+				ParsingErrors: nil,
+			},
+		},
+		{
+			Src: "simple_cgo.go",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 33, 2),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "simplecgo",
+						LocationSpan: newLocationSpan(1, 0, 1, 18),
+						Span:         smgo.RuneSpan{0, 17},
+					},
+					&smgo.Container{
+						Type:         smgo.CgoPreambleNode,
+						Name:         "C",
+						LocationSpan: newLocationSpan(2, 0, 21, 11),
+						HeaderSpan:   smgo.RuneSpan{18, 21},
+						FooterSpan:   smgo.RuneSpan{285, 298},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.CgoDeclNode,
+								Name:         "#cgo LDFLAGS",
+								LocationSpan: newLocationSpan(4, 0, 4, 18),
+								Span:         smgo.RuneSpan{22, 39},
+							},
+							&smgo.Terminal{
+								Type:         smgo.CgoDeclNode,
+								Name:         "#include <math.h>",
+								LocationSpan: newLocationSpan(5, 0, 5, 18),
+								Span:         smgo.RuneSpan{40, 57},
+							},
+							&smgo.Terminal{
+								Type:         smgo.CgoDeclNode,
+								Name:         "#include <stdlib.h>",
+								LocationSpan: newLocationSpan(6, 0, 6, 20),
+								Span:         smgo.RuneSpan{58, 77},
+							},
+							&smgo.Terminal{
+								Type:         smgo.CgoDeclNode,
+								Name:         "#define SQUARE",
+								LocationSpan: newLocationSpan(7, 0, 8, 30),
+								Span:         smgo.RuneSpan{78, 108},
+							},
+							&smgo.Terminal{
+								Type:         smgo.CgoDeclNode,
+								Name:         "point",
+								LocationSpan: newLocationSpan(9, 0, 13, 9),
+								Span:         smgo.RuneSpan{109, 171},
+							},
+							&smgo.Terminal{
+								Type:         smgo.CgoDeclNode,
+								Name:         "norm",
+								LocationSpan: newLocationSpan(14, 0, 17, 2),
+								Span:         smgo.RuneSpan{172, 245},
+							},
+							&smgo.Terminal{
+								Type:         smgo.CgoDeclNode,
+								Name:         "greeting",
+								LocationSpan: newLocationSpan(18, 0, 19, 38),
+								Span:         smgo.RuneSpan{246, 284},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.ImportNode,
+						Name:         "import",
+						LocationSpan: newLocationSpan(22, 0, 29, 2),
+						HeaderSpan:   smgo.RuneSpan{299, 308},
+						FooterSpan:   smgo.RuneSpan{381, 382},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.ImportNode,
+								Name:         "fmt",
+								LocationSpan: newLocationSpan(24, 0, 24, 7),
+								Span:         smgo.RuneSpan{309, 315},
+							},
+							&smgo.Container{
+								Type:         smgo.CgoPreambleNode,
+								Name:         "C",
+								LocationSpan: newLocationSpan(25, 0, 28, 5),
+								HeaderSpan:   smgo.RuneSpan{316, 319},
+								FooterSpan:   smgo.RuneSpan{376, 380},
+								Children: []smgo.Node{
+									&smgo.Terminal{
+										Type:         smgo.CgoDeclNode,
+										Name:         "#include <stdio.h>",
+										LocationSpan: newLocationSpan(26, 3, 26, 23),
+										Span:         smgo.RuneSpan{320, 339},
+									},
+									&smgo.Terminal{
+										Type:         smgo.CgoDeclNode,
+										Name:         "answer",
+										LocationSpan: newLocationSpan(27, 0, 27, 36),
+										Span:         smgo.RuneSpan{340, 375},
+									},
+								},
+							},
+						},
+					},
+					&smgo.Terminal{
+						Type:         smgo.FunctionNode,
+						Name:         "Norm",
+						LocationSpan: newLocationSpan(30, 0, 33, 2),
+						Span:         smgo.RuneSpan{383, 478},
+					},
+				},
+				ParsingErrors: nil,
+			},
+		},
 		{
 			Src: "simple_const.go",
 			ExpectedFile: &smgo.File{
@@ -49,6 +411,196 @@ func TestParseSimpleCases(t *testing.T) {
 				ParsingErrors: nil,
 			},
 		},
+		{
+			Src: "simple_duplicates.go",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 26, 28),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "simpleduplicates",
+						LocationSpan: newLocationSpan(1, 0, 1, 25),
+						Span:         smgo.RuneSpan{0, 24},
+					},
+					&smgo.Terminal{
+						Type:         smgo.ImportNode,
+						Name:         "_ embed",
+						LocationSpan: newLocationSpan(2, 0, 3, 17),
+						Span:         smgo.RuneSpan{25, 42},
+						LocalName:    "_",
+					},
+					&smgo.Terminal{
+						Type:         smgo.ImportNode,
+						Name:         "_ net/http/pprof",
+						LocationSpan: newLocationSpan(4, 0, 5, 26),
+						Span:         smgo.RuneSpan{43, 69},
+						LocalName:    "_",
+					},
+					&smgo.Container{
+						Type:         smgo.StructNode,
+						Name:         "T",
+						LocationSpan: newLocationSpan(6, 0, 12, 2),
+						HeaderSpan:   smgo.RuneSpan{70, 86},
+						FooterSpan:   smgo.RuneSpan{125, 126},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.FieldNode,
+								Name:         "_ (_ [4]byte)",
+								LocationSpan: newLocationSpan(8, 0, 8, 11),
+								Span:         smgo.RuneSpan{87, 97},
+							},
+							&smgo.Terminal{
+								Type:         smgo.FieldNode,
+								Name:         "A",
+								LocationSpan: newLocationSpan(9, 0, 9, 7),
+								Span:         smgo.RuneSpan{98, 104},
+							},
+							&smgo.Terminal{
+								Type:         smgo.FieldNode,
+								Name:         "_ (_ [4]byte)#2",
+								LocationSpan: newLocationSpan(10, 0, 10, 11),
+								Span:         smgo.RuneSpan{105, 115},
+							},
+							&smgo.Terminal{
+								Type:         smgo.FieldNode,
+								Name:         "_ (_ int32)",
+								LocationSpan: newLocationSpan(11, 0, 11, 9),
+								Span:         smgo.RuneSpan{116, 124},
+							},
+						},
+					},
+					&smgo.Terminal{
+						Type:         smgo.VarNode,
+						Name:         "_ (var _ io.Reader = (*T)(nil))",
+						LocationSpan: newLocationSpan(13, 0, 14, 28),
+						Span:         smgo.RuneSpan{127, 155},
+					},
+					&smgo.Terminal{
+						Type:         smgo.VarNode,
+						Name:         "_ (var _ io.Writer = (*T)(nil))",
+						LocationSpan: newLocationSpan(15, 0, 16, 28),
+						Span:         smgo.RuneSpan{156, 184},
+					},
+					&smgo.Terminal{
+						Type:         smgo.FunctionNode,
+						Name:         "init",
+						LocationSpan: newLocationSpan(17, 0, 20, 2),
+						Span:         smgo.RuneSpan{185, 217},
+					},
+					&smgo.Terminal{
+						Type:         smgo.FunctionNode,
+						Name:         "init#2",
+						LocationSpan: newLocationSpan(21, 0, 24, 2),
+						Span:         smgo.RuneSpan{218, 251},
+					},
+					&smgo.Terminal{
+						Type:         smgo.VarNode,
+						Name:         "_ (var _ io.Reader = (*T)(nil))#2",
+						LocationSpan: newLocationSpan(25, 0, 26, 28),
+						Span:         smgo.RuneSpan{252, 280},
+					},
+				},
+				ParsingErrors: nil,
+			},
+		},
+		{
+			Src: "simple_embedded.go",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 19, 2),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "simpleembedded",
+						LocationSpan: newLocationSpan(1, 0, 1, 23),
+						Span:         smgo.RuneSpan{0, 22},
+					},
+					&smgo.Container{
+						Type:         smgo.ImportNode,
+						Name:         "import",
+						LocationSpan: newLocationSpan(2, 0, 7, 2),
+						HeaderSpan:   smgo.RuneSpan{23, 32},
+						FooterSpan:   smgo.RuneSpan{56, 57},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.ImportNode,
+								Name:         "bytes",
+								LocationSpan: newLocationSpan(4, 0, 4, 9),
+								Span:         smgo.RuneSpan{33, 41},
+							},
+							&smgo.Terminal{
+								Type:         smgo.ImportNode,
+								Name:         "io",
+								LocationSpan: newLocationSpan(5, 0, 5, 6),
+								Span:         smgo.RuneSpan{42, 47},
+							},
+							&smgo.Terminal{
+								Type:         smgo.ImportNode,
+								Name:         "sync",
+								LocationSpan: newLocationSpan(6, 0, 6, 8),
+								Span:         smgo.RuneSpan{48, 55},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.StructNode,
+						Name:         "Buffer",
+						LocationSpan: newLocationSpan(8, 0, 14, 2),
+						HeaderSpan:   smgo.RuneSpan{58, 79},
+						FooterSpan:   smgo.RuneSpan{150, 151},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.EmbeddedFieldNode,
+								Name:         "sync.Mutex",
+								LocationSpan: newLocationSpan(10, 0, 10, 12),
+								Span:         smgo.RuneSpan{80, 91},
+							},
+							&smgo.Terminal{
+								Type:         smgo.EmbeddedFieldNode,
+								Name:         "*bytes.Buffer",
+								LocationSpan: newLocationSpan(11, 0, 11, 15),
+								Span:         smgo.RuneSpan{92, 106},
+							},
+							&smgo.Terminal{
+								Type:         smgo.EmbeddedFieldNode,
+								Name:         "List[int]",
+								LocationSpan: newLocationSpan(12, 0, 12, 25),
+								Span:         smgo.RuneSpan{107, 131},
+							},
+							&smgo.Terminal{
+								Type:         smgo.FieldNode,
+								Name:         "Name",
+								LocationSpan: newLocationSpan(13, 0, 13, 18),
+								Span:         smgo.RuneSpan{132, 149},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.InterfaceNode,
+						Name:         "ReadCloser",
+						LocationSpan: newLocationSpan(15, 0, 19, 2),
+						HeaderSpan:   smgo.RuneSpan{152, 180},
+						FooterSpan:   smgo.RuneSpan{207, 208},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.EmbeddedInterfaceNode,
+								Name:         "io.Reader",
+								LocationSpan: newLocationSpan(17, 0, 17, 11),
+								Span:         smgo.RuneSpan{181, 191},
+							},
+							&smgo.Terminal{
+								Type:         smgo.MethodSpecNode,
+								Name:         "Close",
+								LocationSpan: newLocationSpan(18, 0, 18, 15),
+								Span:         smgo.RuneSpan{192, 206},
+							},
+						},
+					},
+				},
+				ParsingErrors: nil,
+			},
+		},
 		{
 			Src: "simple_footer.go_src",
 			ExpectedFile: &smgo.File{
@@ -135,7 +687,7 @@ func TestParseSimpleCases(t *testing.T) {
 						FooterSpan:   smgo.RuneSpan{65, 66},
 						Children: []smgo.Node{
 							&smgo.Terminal{
-								Type:         smgo.FieldNode,
+								Type:         smgo.MethodSpecNode,
 								Name:         "Area",
 								LocationSpan: newLocationSpan(4, 0, 4, 16),
 								Span:         smgo.RuneSpan{49, 64},
@@ -146,6 +698,187 @@ func TestParseSimpleCases(t *testing.T) {
 				ParsingErrors: nil,
 			},
 		},
+		{
+			Src: "simple_method.go",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 20, 2),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "simplemethod",
+						LocationSpan: newLocationSpan(1, 0, 1, 21),
+						Span:         smgo.RuneSpan{0, 20},
+					},
+					&smgo.Terminal{
+						Type:         smgo.MethodNode,
+						Name:         "(*Reader).Close",
+						LocationSpan: newLocationSpan(2, 0, 5, 2),
+						Span:         smgo.RuneSpan{21, 68},
+					},
+					&smgo.Terminal{
+						Type:         smgo.MethodNode,
+						Name:         "(*Writer).Close",
+						LocationSpan: newLocationSpan(6, 0, 9, 2),
+						Span:         smgo.RuneSpan{69, 116},
+					},
+					&smgo.Terminal{
+						Type:         smgo.MethodNode,
+						Name:         "Writer.Len",
+						LocationSpan: newLocationSpan(10, 0, 13, 2),
+						Span:         smgo.RuneSpan{117, 157},
+					},
+					&smgo.Terminal{
+						Type:         smgo.MethodNode,
+						Name:         "(*List).Push",
+						LocationSpan: newLocationSpan(14, 0, 16, 2),
+						Span:         smgo.RuneSpan{158, 190},
+					},
+					&smgo.Terminal{
+						Type:         smgo.MethodNode,
+						Name:         "Pair.Key",
+						LocationSpan: newLocationSpan(17, 0, 20, 2),
+						Span:         smgo.RuneSpan{191, 235},
+					},
+				},
+				ParsingErrors: nil,
+			},
+		},
+		{
+			Src: "simple_multiname.go",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 15, 2),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "simplemultiname",
+						LocationSpan: newLocationSpan(1, 0, 1, 24),
+						Span:         smgo.RuneSpan{0, 23},
+					},
+					&smgo.Terminal{
+						Type:         smgo.VarNode,
+						Name:         "a, b, c",
+						LocationSpan: newLocationSpan(2, 0, 3, 16),
+						Span:         smgo.RuneSpan{24, 40},
+						Names:        []string{"a", "b", "c"},
+					},
+					&smgo.Terminal{
+						Type:         smgo.ConstNode,
+						Name:         "X, Y",
+						LocationSpan: newLocationSpan(4, 0, 5, 18),
+						Span:         smgo.RuneSpan{41, 59},
+						Names:        []string{"X", "Y"},
+					},
+					&smgo.Container{
+						Type:         smgo.StructNode,
+						Name:         "Point",
+						LocationSpan: newLocationSpan(6, 0, 10, 2),
+						HeaderSpan:   smgo.RuneSpan{60, 80},
+						FooterSpan:   smgo.RuneSpan{109, 110},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.FieldNode,
+								Name:         "X, Y",
+								LocationSpan: newLocationSpan(8, 0, 8, 14),
+								Span:         smgo.RuneSpan{81, 94},
+								Names:        []string{"X", "Y"},
+							},
+							&smgo.Terminal{
+								Type:         smgo.FieldNode,
+								Name:         "Z",
+								LocationSpan: newLocationSpan(9, 0, 9, 14),
+								Span:         smgo.RuneSpan{95, 108},
+							},
+						},
+					},
+					&smgo.Container{
+						Type:         smgo.VarNode,
+						Name:         "var",
+						LocationSpan: newLocationSpan(11, 0, 15, 2),
+						HeaderSpan:   smgo.RuneSpan{111, 117},
+						FooterSpan:   smgo.RuneSpan{144, 145},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.VarNode,
+								Name:         "d, e",
+								LocationSpan: newLocationSpan(13, 0, 13, 13),
+								Span:         smgo.RuneSpan{118, 130},
+								Names:        []string{"d", "e"},
+							},
+							&smgo.Terminal{
+								Type:         smgo.VarNode,
+								Name:         "f",
+								LocationSpan: newLocationSpan(14, 0, 14, 13),
+								Span:         smgo.RuneSpan{131, 143},
+							},
+						},
+					},
+				},
+				ParsingErrors: nil,
+			},
+		},
+		{
+			Src: "simple_nonascii.go",
+			ExpectedFile: &smgo.File{
+				LocationSpan: newLocationSpan(1, 0, 21, 2),
+				FooterSpan:   smgo.RuneSpan{0, -1},
+				Children: []smgo.Node{
+					&smgo.Terminal{
+						Type:         smgo.PackageNode,
+						Name:         "nonascii",
+						LocationSpan: newLocationSpan(1, 0, 2, 17),
+						Span:         smgo.RuneSpan{0, 87},
+					},
+					&smgo.Terminal{
+						Type:         smgo.ConstNode,
+						Name:         "挨拶",
+						LocationSpan: newLocationSpan(3, 0, 5, 19),
+						Span:         smgo.RuneSpan{88, 125},
+					},
+					&smgo.Terminal{
+						Type:         smgo.TypeNode,
+						Name:         "Año",
+						LocationSpan: newLocationSpan(6, 0, 8, 13),
+						Span:         smgo.RuneSpan{126, 157},
+					},
+					&smgo.Terminal{
+						Type:         smgo.VarNode,
+						Name:         "Emoji",
+						LocationSpan: newLocationSpan(9, 0, 11, 19),
+						Span:         smgo.RuneSpan{158, 238},
+					},
+					&smgo.Terminal{
+						Type:         smgo.FunctionNode,
+						Name:         "Señal",
+						LocationSpan: newLocationSpan(12, 0, 16, 2),
+						Span:         smgo.RuneSpan{239, 316},
+					},
+					&smgo.Container{
+						Type:         smgo.StructNode,
+						Name:         "Canción",
+						LocationSpan: newLocationSpan(17, 0, 21, 2),
+						HeaderSpan:   smgo.RuneSpan{317, 339},
+						FooterSpan:   smgo.RuneSpan{377, 378},
+						Children: []smgo.Node{
+							&smgo.Terminal{
+								Type:         smgo.FieldNode,
+								Name:         "Título",
+								LocationSpan: newLocationSpan(19, 0, 19, 25),
+								Span:         smgo.RuneSpan{340, 364},
+							},
+							&smgo.Terminal{
+								Type:         smgo.FieldNode,
+								Name:         "Año",
+								LocationSpan: newLocationSpan(20, 0, 20, 12),
+								Span:         smgo.RuneSpan{365, 376},
+							},
+						},
+					},
+				},
+				ParsingErrors: nil,
+			},
+		},
 		{
 			Src: "simple_struct.go",
 			ExpectedFile: &smgo.File{
@@ -174,8 +907,8 @@ func TestParseSimpleCases(t *testing.T) {
 						},
 					},
 					&smgo.Terminal{
-						Type:         smgo.FunctionNode,
-						Name:         "SayHi",
+						Type:         smgo.MethodNode,
+						Name:         "(*Person).SayHi",
 						LocationSpan: newLocationSpan(6, 0, 9, 2),
 						Span:         smgo.RuneSpan{58, 115},
 					},
@@ -208,7 +941,7 @@ func TestParseSimpleCases(t *testing.T) {
 						Span:         smgo.RuneSpan{33, 52},
 					},
 					&smgo.Terminal{
-						Type:         smgo.TypeNode,
+						Type:         smgo.AliasNode,
 						Name:         "StringAlias",
 						LocationSpan: newLocationSpan(6, 0, 7, 26),
 						Span:         smgo.RuneSpan{53, 79},
@@ -291,15 +1024,15 @@ func TestParseSimpleCases(t *testing.T) {
 	for _, simpleCase := range simpleCases {
 		name := simpleCase.Src[len("simple_"):strings.LastIndex(simpleCase.Src, ".")]
 		t.Run(name, func(t *testing.T) {
-			srcFile, err := os.Open("testdata/" + simpleCase.Src)
+			src, err := ioutil.ReadFile("testdata/" + simpleCase.Src)
 			require.Nil(t, err)
-			defer srcFile.Close()
 
-			file, err := smgo.Parse(srcFile, "UTF-8")
+			file, err := parser.ParseBytes(context.Background(), src, "UTF-8")
 			assert.NotNil(t, file)
 			assert.Nil(t, err)
 
 			assert.Equal(t, simpleCase.ExpectedFile, file)
+			assertTiling(t, file, src)
 			if t.Failed() {
 				spew.Dump(t.Name(), file)
 			}
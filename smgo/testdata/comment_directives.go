@@ -0,0 +1,34 @@
+// Copyright notice.
+
+//go:build linux && !386
+// +build linux,!386
+
+// Package commentdirectives has directives.
+package commentdirectives
+
+import (
+	_ "embed"
+	"unsafe"
+)
+
+//go:generate stringer -type=Kind
+
+// Kind of things.
+type Kind int
+
+//go:embed comment_directives.go
+var src string
+
+// now returns the time.
+//
+//go:linkname now time.now
+//go:noinline
+func now() (int64, int32, int64)
+
+//go:notinheap
+type T struct {
+	A unsafe.Pointer
+}
+
+//go:linkname linked
+var linked string // set elsewhere
@@ -0,0 +1,26 @@
+package genericconstraints
+
+import "fmt"
+
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+type Stringish interface {
+	~string
+	fmt.Stringer
+	comparable
+}
+
+type Bytes interface {
+	[]byte | string
+	Len() int
+}
+
+func Sum[T Number](values ...T) T {
+	var s T
+	for _, v := range values {
+		s += v
+	}
+	return s
+}
@@ -0,0 +1,13 @@
+package genericfunc
+
+func Map[T, U any](s []T, f func(T) U) []U {
+	r := make([]U, 0, len(s))
+	for _, v := range s {
+		r = append(r, f(v))
+	}
+	return r
+}
+
+func Keys[M ~map[K]V, K comparable, V any](m M) []K {
+	return nil
+}
@@ -0,0 +1,22 @@
+package generictypes
+
+type Set[T comparable] struct {
+	items map[T]struct{}
+}
+
+type List[T any] []T
+
+type (
+	Pair[K comparable, V any] struct {
+		Key   K
+		Value V
+	}
+
+	Tree[T interface{ Less(T) bool }] struct {
+		Left, Right *Tree[T]
+	}
+)
+
+func (s *Set[T]) Add(v T) {
+	s.items[v] = struct{}{}
+}
@@ -0,0 +1,20 @@
+package simplealias
+
+import "io"
+
+type Reader = io.Reader
+
+type Set[T comparable] = map[T]struct{}
+
+type Point = struct {
+	X, Y int
+}
+
+type Closer = interface {
+	Close() error
+}
+
+type (
+	Defined int
+	Alias   = int
+)
@@ -0,0 +1,34 @@
+package simpleanonymous
+
+type Server struct {
+	Name   string
+	Config struct {
+		Host string
+		Port int
+		TLS  struct {
+			Cert, Key string
+		} `json:"tls"`
+	}
+	Handler interface {
+		Serve() error
+	}
+}
+
+var opts struct {
+	Verbose bool
+}
+
+var (
+	a, b struct{ X int }
+	c    int
+)
+
+var x interface{ m() } = struct /* c */ {
+	m func()
+}{}
+
+var (
+	y struct{ A int } = struct /* c */ {
+		A int
+	}{}
+)
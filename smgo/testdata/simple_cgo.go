@@ -0,0 +1,33 @@
+package simplecgo
+
+/*
+#cgo LDFLAGS: -lm
+#include <math.h>
+#include <stdlib.h>
+
+#define SQUARE(x) ((x) * (x))
+
+// point in the plane
+typedef struct {
+	double x, y;
+} point;
+
+static double norm(point p) {
+	return sqrt(SQUARE(p.x) + SQUARE(p.y));
+}
+
+static const char *greeting = "{hi}";
+*/
+import "C"
+
+import (
+	"fmt"
+
+	// #include <stdio.h>
+	// int answer(void) { return 42; }
+	"C"
+)
+
+func Norm(x, y float64) float64 {
+	return float64(C.norm(C.point{C.double(x), C.double(y)}))
+}
@@ -0,0 +1,26 @@
+package simpleduplicates
+
+import _ "embed"
+
+import _ "net/http/pprof"
+
+type T struct {
+	_ [4]byte
+	A int
+	_ [4]byte
+	_ int32
+}
+
+var _ io.Reader = (*T)(nil)
+
+var _ io.Writer = (*T)(nil)
+
+func init() {
+	print("first")
+}
+
+func init() {
+	print("second")
+}
+
+var _ io.Reader = (*T)(nil)
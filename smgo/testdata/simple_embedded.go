@@ -0,0 +1,19 @@
+package simpleembedded
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+type Buffer struct {
+	sync.Mutex
+	*bytes.Buffer
+	List[int] `json:"list"`
+	Name      string
+}
+
+type ReadCloser interface {
+	io.Reader
+	Close() error
+}
@@ -0,0 +1,20 @@
+package simplemethod
+
+func (r *Reader) Close() error {
+	return nil
+}
+
+func (w *Writer) Close() error {
+	return nil
+}
+
+func (w Writer) Len() int {
+	return 0
+}
+
+func (l *List[T]) Push(v T) {
+}
+
+func (p Pair[K, V]) Key() (k K) {
+	return
+}
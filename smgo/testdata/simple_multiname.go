@@ -0,0 +1,15 @@
+package simplemultiname
+
+var a, b, c int
+
+const X, Y = 1, 2
+
+type Point struct {
+	X, Y float64
+	Z    float64
+}
+
+var (
+	d, e = 1, 2
+	f    string
+)
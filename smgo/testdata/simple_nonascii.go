@@ -0,0 +1,21 @@
+// Paquete nonascii tiene comentarios en español: año, señal, canción.
+package nonascii
+
+// 挨拶 は日本語の識別子です。
+const 挨拶 = "こんにちは"
+
+// Año es un año.
+type Año int
+
+// Emoji is outside the BMP: 😀 takes two UTF-16 code units.
+var Emoji = "😀😀"
+
+// Señal devuelve la señal.
+func Señal(año Año) string {
+	return "¡señal!"
+}
+
+type Canción struct {
+	Título string // título
+	Año    Año
+}
@@ -0,0 +1,173 @@
+package smgo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Violation is a broken invariant of a declarations tree.
+type Violation struct {
+	// Node is the name of the node, empty for the file itself.
+	Node    string
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Node == "" {
+		return "file: " + v.Message
+	}
+	return v.Node + ": " + v.Message
+}
+
+// ValidationError is returned by a Parser created WithValidation when the tree is invalid.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.String())
+	}
+	return "invalid declarations tree: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks the invariants SemanticMerge expects from the tree of src (as returned by Decode, with spans in
+// characters): the spans of headers, children and footers are contiguous and don't overlap, the file footer closes
+// the tiling, and every LocationSpan agrees with its span.
+func Validate(file *File, src []byte) []Violation {
+	return validate(file, src, Chars)
+}
+
+func validate(file *File, src []byte, unit SpanUnit) []Violation {
+	v := &validator{
+		lines: newLineIndex(src, unit),
+	}
+	v.nodes(file.Children)
+	if v.offset < v.lines.length {
+		if file.FooterSpan != (RuneSpan{v.offset, v.lines.length - 1}) {
+			v.add("", "footer %s doesn't cover the end of the file, expected %s", file.FooterSpan,
+				RuneSpan{v.offset, v.lines.length - 1})
+		}
+	} else if file.FooterSpan.End >= file.FooterSpan.Start {
+		v.add("", "footer %s beyond the end of the file", file.FooterSpan)
+	}
+	if v.offset > v.lines.length {
+		v.add("", "nodes end at %d, beyond the end of the file (%d)", v.offset, v.lines.length)
+	}
+	if file.LocationSpan.Start != (Location{1, 0}) {
+		v.add("", "location %s doesn't start at the beginning of the file", file.LocationSpan)
+	}
+	if v.lines.length > 0 {
+		v.checkEnd("", file.LocationSpan.End, RuneSpan{0, v.lines.length - 1})
+	}
+	return v.violations
+}
+
+type validator struct {
+	lines      *lineIndex
+	offset     int
+	violations []Violation
+}
+
+func (v *validator) add(node, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{
+		Node:    node,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// span checks that s starts at the current offset and advances it.
+func (v *validator) span(node, what string, s RuneSpan, allowEmpty bool) {
+	if s.Start != v.offset {
+		if s.Start > v.offset {
+			v.add(node, "%s %s leaves a gap after %d", what, s, v.offset-1)
+		} else {
+			v.add(node, "%s %s overlaps the previous span, expected start %d", what, s, v.offset)
+		}
+	}
+	if s.End < s.Start-1 || !allowEmpty && s.End < s.Start {
+		v.add(node, "%s %s is empty", what, s)
+	}
+	v.offset = s.End + 1
+}
+
+// checkStart checks that the start location agrees with offset.
+func (v *validator) checkStart(node string, start Location, offset int) {
+	if expected := v.lines.location(offset); start != expected {
+		v.add(node, "start location %s doesn't match offset %d, expected %s", start, offset, expected)
+	}
+}
+
+// checkEnd checks that the end location, pointing to the character after the node, is inside s or right after it.
+func (v *validator) checkEnd(node string, end Location, s RuneSpan) {
+	offset, ok := v.lines.offset(Location{end.Line, end.Column - 1})
+	if !ok || offset < s.Start || offset > s.End+1 {
+		v.add(node, "end location %s is outside %s", end, s)
+	}
+}
+
+func (v *validator) nodes(nodes []Node) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *Terminal:
+			v.span(n.Name, "span", n.Span, false)
+			v.checkStart(n.Name, n.LocationSpan.Start, n.Span.Start)
+			v.checkEnd(n.Name, n.LocationSpan.End, n.Span)
+		case *Container:
+			v.span(n.Name, "header", n.HeaderSpan, false)
+			v.checkStart(n.Name, n.LocationSpan.Start, n.HeaderSpan.Start)
+			v.nodes(n.Children)
+			v.span(n.Name, "footer", n.FooterSpan, true)
+			v.checkEnd(n.Name, n.LocationSpan.End, RuneSpan{n.HeaderSpan.Start, n.FooterSpan.End})
+		}
+	}
+}
+
+// lineIndex converts between offsets and locations of a source in a given unit.
+type lineIndex struct {
+	// starts[i] is the offset of the line i+1
+	starts []int
+	length int
+}
+
+func newLineIndex(src []byte, unit SpanUnit) *lineIndex {
+	li := &lineIndex{
+		starts: []int{0},
+	}
+	if unit == Bytes {
+		for i, b := range src {
+			if b == '\n' {
+				li.starts = append(li.starts, i+1)
+			}
+		}
+		li.length = len(src)
+		return li
+	}
+	m := newPositionMap(src)
+	for _, start := range m.lineStarts[1:] {
+		li.starts = append(li.starts, m.offset(start))
+	}
+	li.length = m.offset(len(src))
+	return li
+}
+
+// location returns the location of offset, with a 0-based column.
+func (li *lineIndex) location(offset int) Location {
+	line := sort.Search(len(li.starts), func(i int) bool {
+		return li.starts[i] > offset
+	})
+	if line == 0 {
+		return Location{1, offset}
+	}
+	return Location{line, offset - li.starts[line-1]}
+}
+
+// offset returns the offset of the location l, with a 0-based column.
+func (li *lineIndex) offset(l Location) (int, bool) {
+	if l.Line < 1 || l.Line > len(li.starts) || l.Column < 0 {
+		return 0, false
+	}
+	return li.starts[l.Line-1] + l.Column, true
+}